@@ -32,6 +32,7 @@ func main() {
 
 	// Initialize notification service
 	svc := services.New(dbConn, logger, cfg)
+	defer svc.Close()
 	var wg sync.WaitGroup
 	svc.Start(&wg)
 
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"notification-service/internal/db"
+	"notification-service/internal/kafka"
 	"notification-service/internal/logging"
 	"notification-service/internal/models"
 	"notification-service/internal/services"
@@ -102,6 +103,12 @@ func (h *Handler) CreateContactPoint(c *gin.Context) {
 		Status:        "active",
 	}
 
+	if err := h.svc.ValidateContactPoint(c.Request.Context(), contactPoint); err != nil {
+		h.logger.Errorf("invalid contact point configuration: %v", err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, err.Error(), nil})
+		return
+	}
+
 	created, err := h.db.CreateContactPoint(c.Request.Context(), contactPoint)
 	if err != nil {
 		h.logger.Errorf("failed to create contact point: %v", err)
@@ -226,6 +233,14 @@ func (h *Handler) UpdateContactPoint(c *gin.Context) {
 
 	copy(contactPoint.ID[:], parsedPathID[:])
 
+	if input.Type != "" || input.Configuration != nil {
+		if err := h.svc.ValidateContactPoint(c.Request.Context(), contactPoint); err != nil {
+			h.logger.Errorf("invalid configuration for contact point %s: %v", id, err)
+			c.JSON(http.StatusBadRequest, StandardResponse{false, err.Error(), nil})
+			return
+		}
+	}
+
 	if err := h.db.UpdateContactPoint(c.Request.Context(), contactPoint); err != nil {
 		h.logger.Errorf("failed to update contact point %s: %v", id, err)
 		c.JSON(http.StatusInternalServerError, StandardResponse{false, "could not update contact point", nil})
@@ -509,6 +524,491 @@ func (h *Handler) GetAlertByUserIDAndStationID(c *gin.Context) {
 	c.JSON(http.StatusOK, StandardResponse{true, "alert list", PaginatedResponse{total, items}})
 }
 
+// GetVAPIDPublicKey returns the application server key for pushManager.subscribe
+func (h *Handler) GetVAPIDPublicKey(c *gin.Context) {
+	key, err := h.svc.VAPIDPublicKey()
+	if err != nil {
+		h.logger.Errorf("web push is not configured: %v", err)
+		c.JSON(http.StatusServiceUnavailable, StandardResponse{false, "web push is not configured", nil})
+		return
+	}
+	c.JSON(http.StatusOK, StandardResponse{true, "vapid public key", gin.H{"public_key": key}})
+}
+
+// SubscribeWebPush registers a browser push subscription as a webpush contact point
+func (h *Handler) SubscribeWebPush(c *gin.Context) {
+	var input models.WebPushSubscribe
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Errorf("invalid web push subscribe payload: %v", err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "invalid request body", nil})
+		return
+	}
+
+	// Browsers re-send the same subscription on every page load
+	if existing, err := h.db.GetWebPushContactPoint(c.Request.Context(), input.UserID, input.Subscription.Endpoint); err == nil {
+		h.logger.Infof("web push subscription already registered as contact point %s", uuid.UUID(existing.ID).String())
+		c.JSON(http.StatusOK, StandardResponse{true, "subscription already registered", existing})
+		return
+	}
+
+	name := input.Name
+	if name == "" {
+		name = "Browser"
+	}
+	contactPoint := models.ContactPoint{
+		Name:   name,
+		UserID: input.UserID,
+		Type:   "webpush",
+		Configuration: map[string]interface{}{
+			"endpoint": input.Subscription.Endpoint,
+			"p256dh":   input.Subscription.Keys.P256dh,
+			"auth":     input.Subscription.Keys.Auth,
+		},
+		Status: "active",
+	}
+
+	if err := h.svc.ValidateContactPoint(c.Request.Context(), contactPoint); err != nil {
+		h.logger.Errorf("invalid web push subscription: %v", err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, err.Error(), nil})
+		return
+	}
+
+	created, err := h.db.CreateContactPoint(c.Request.Context(), contactPoint)
+	if err != nil {
+		h.logger.Errorf("failed to register web push subscription: %v", err)
+		c.JSON(http.StatusInternalServerError, StandardResponse{false, "could not register subscription", nil})
+		return
+	}
+
+	h.logger.Infof("registered web push subscription as contact point %s", uuid.UUID(created.ID).String())
+	c.JSON(http.StatusCreated, StandardResponse{true, "subscription registered", created})
+}
+
+// UnsubscribeWebPush removes the webpush contact points for a browser endpoint
+func (h *Handler) UnsubscribeWebPush(c *gin.Context) {
+	var input models.WebPushUnsubscribe
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Errorf("invalid web push unsubscribe payload: %v", err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "invalid request body", nil})
+		return
+	}
+
+	removed, err := h.db.DeleteWebPushContactPoints(c.Request.Context(), input.UserID, input.Endpoint)
+	if err != nil {
+		h.logger.Errorf("failed to unregister web push subscription for user %d: %v", input.UserID, err)
+		c.JSON(http.StatusInternalServerError, StandardResponse{false, "could not unregister subscription", nil})
+		return
+	}
+	if removed == 0 {
+		c.JSON(http.StatusNotFound, StandardResponse{false, "subscription not found", nil})
+		return
+	}
+
+	h.logger.Infof("unregistered %d web push subscription(s) for user %d", removed, input.UserID)
+	c.Status(http.StatusNoContent)
+}
+
+// CreateTelegramLink issues a one-time link that binds a Telegram chat to a contact point
+func (h *Handler) CreateTelegramLink(c *gin.Context) {
+	var input models.TelegramLinkCreate
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Errorf("invalid telegram link payload: %v", err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "invalid request body", nil})
+		return
+	}
+	if !h.svc.TelegramLinkingEnabled() {
+		c.JSON(http.StatusServiceUnavailable, StandardResponse{false, "telegram linking is not configured", nil})
+		return
+	}
+
+	var contactPoint models.ContactPoint
+	if input.ContactPointID != "" {
+		// Re-bind an existing contact point, e.g. to move it to another chat
+		cp, err := h.db.GetContactPointByID(c.Request.Context(), input.ContactPointID)
+		if err != nil || cp.UserID != input.UserID {
+			c.JSON(http.StatusNotFound, StandardResponse{false, "contact point not found", nil})
+			return
+		}
+		if cp.Type != "telegram" {
+			c.JSON(http.StatusBadRequest, StandardResponse{false, "contact point is not a telegram contact point", nil})
+			return
+		}
+		// The chat is bound through the service bot, which a contact point with its own bot would not use
+		if token, _ := cp.Configuration["bot_token"].(string); token != "" {
+			c.JSON(http.StatusBadRequest, StandardResponse{false, "contact point uses its own bot_token", nil})
+			return
+		}
+		contactPoint = cp
+	} else {
+		name := input.Name
+		if name == "" {
+			name = "Telegram"
+		}
+		// Pending until the chat is bound, so no policy dispatches to it before that
+		created, err := h.db.CreateContactPoint(c.Request.Context(), models.ContactPoint{
+			Name:          name,
+			UserID:        input.UserID,
+			Type:          "telegram",
+			Configuration: map[string]interface{}{},
+			Status:        "pending",
+		})
+		if err != nil {
+			h.logger.Errorf("failed to create telegram contact point: %v", err)
+			c.JSON(http.StatusInternalServerError, StandardResponse{false, "could not create contact point", nil})
+			return
+		}
+		contactPoint = created
+	}
+
+	link, err := h.svc.IssueTelegramLink(c.Request.Context(), contactPoint.ID)
+	if err != nil {
+		h.logger.Errorf("failed to issue telegram link for contact point %s: %v", uuid.UUID(contactPoint.ID).String(), err)
+		c.JSON(http.StatusInternalServerError, StandardResponse{false, "could not issue telegram link", nil})
+		return
+	}
+	c.JSON(http.StatusCreated, StandardResponse{true, "telegram link issued", link})
+}
+
+// TelegramWebhook passes an update Telegram posted for a bot to that bot's handler
+func (h *Handler) TelegramWebhook(c *gin.Context) {
+	botID, err := strconv.ParseInt(c.Param("bot_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "invalid bot_id", nil})
+		return
+	}
+	handler, ok := h.svc.TelegramWebhookHandler(botID)
+	if !ok {
+		h.logger.Warnf("telegram update for unknown bot %d", botID)
+		c.JSON(http.StatusNotFound, StandardResponse{false, "bot not found", nil})
+		return
+	}
+	handler(c.Writer, c.Request)
+}
+
+// CreateTemplate validates and stores a user-defined message template
+func (h *Handler) CreateTemplate(c *gin.Context) {
+	var input models.TemplateCreate
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Errorf("invalid create template payload: %v", err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "invalid request body", nil})
+		return
+	}
+
+	tmpl := models.Template{
+		UserID:  input.UserID,
+		Name:    input.Name,
+		Channel: input.Channel,
+		Subject: input.Subject,
+		Body:    input.Body,
+		HTML:    input.HTML,
+		Status:  "active",
+	}
+
+	if err := h.svc.ValidateTemplate(tmpl); err != nil {
+		h.logger.Errorf("invalid template: %v", err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, err.Error(), nil})
+		return
+	}
+
+	created, err := h.db.CreateTemplate(c.Request.Context(), tmpl)
+	if err != nil {
+		h.logger.Errorf("failed to create template: %v", err)
+		c.JSON(http.StatusInternalServerError, StandardResponse{false, "could not create template", nil})
+		return
+	}
+
+	h.logger.Infof("created template %s", uuid.UUID(created.ID).String())
+	c.JSON(http.StatusCreated, StandardResponse{true, "template created", created})
+}
+
+// GetTemplate retrieves a single active template by UUID
+func (h *Handler) GetTemplate(c *gin.Context) {
+	id := c.Param("id")
+	tmpl, err := h.db.GetTemplateByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Errorf("template %s not found: %v", id, err)
+		c.JSON(http.StatusNotFound, StandardResponse{false, "template not found", nil})
+		return
+	}
+
+	h.logger.Infof("retrieved template %s", id)
+	c.JSON(http.StatusOK, StandardResponse{true, "template retrieved", tmpl})
+}
+
+// GetTemplatesByUserID lists active templates for a user
+func (h *Handler) GetTemplatesByUserID(c *gin.Context) {
+	uid, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		h.logger.Errorf("invalid user_id %s: %v", c.Param("user_id"), err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "invalid user_id", nil})
+		return
+	}
+
+	list, err := h.db.GetTemplatesByUserID(c.Request.Context(), uid)
+	if err != nil {
+		h.logger.Errorf("could not list templates for user %d: %v", uid, err)
+		c.JSON(http.StatusInternalServerError, StandardResponse{false, "failed to fetch templates", nil})
+		return
+	}
+
+	h.logger.Infof("listed %d templates for user %d", len(list), uid)
+	c.JSON(http.StatusOK, StandardResponse{true, "templates list", list})
+}
+
+// UpdateTemplate changes the name or sources of a template, validating them again
+func (h *Handler) UpdateTemplate(c *gin.Context) {
+	id := c.Param("id")
+	var input models.TemplateUpdate
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Errorf("invalid update payload for template %s: %v", id, err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "invalid request body", nil})
+		return
+	}
+
+	tmpl, err := h.db.GetTemplateByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Errorf("template %s not found: %v", id, err)
+		c.JSON(http.StatusNotFound, StandardResponse{false, "template not found", nil})
+		return
+	}
+
+	if input.Name != "" {
+		tmpl.Name = input.Name
+	}
+	if input.Subject != nil {
+		tmpl.Subject = *input.Subject
+	}
+	if input.Body != "" {
+		tmpl.Body = input.Body
+	}
+	if input.HTML != nil {
+		tmpl.HTML = *input.HTML
+	}
+
+	if err := h.svc.ValidateTemplate(tmpl); err != nil {
+		h.logger.Errorf("invalid template %s: %v", id, err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, err.Error(), nil})
+		return
+	}
+
+	if err := h.db.UpdateTemplate(c.Request.Context(), tmpl); err != nil {
+		h.logger.Errorf("failed to update template %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, StandardResponse{false, "could not update template", nil})
+		return
+	}
+
+	updated, err := h.db.GetTemplateByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Errorf("failed to fetch updated template %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, StandardResponse{false, "update succeeded but retrieval failed", nil})
+		return
+	}
+
+	h.logger.Infof("updated template %s", id)
+	c.JSON(http.StatusOK, StandardResponse{true, "template updated", updated})
+}
+
+// DeleteTemplate marks a template as deleted, its contact points and policies fall back to the built-in format
+func (h *Handler) DeleteTemplate(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.db.DeleteTemplate(c.Request.Context(), id); err != nil {
+		h.logger.Errorf("failed to delete template %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, StandardResponse{false, "could not delete template", nil})
+		return
+	}
+
+	h.logger.Infof("deleted template %s", id)
+	c.Status(http.StatusNoContent)
+}
+
+// AttachTemplate makes a contact point, or a policy, render its notifications with a template
+func (h *Handler) AttachTemplate(c *gin.Context) {
+	id := c.Param("id")
+	var input models.TemplateAttachment
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Errorf("invalid attach payload for template %s: %v", id, err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "invalid request body", nil})
+		return
+	}
+
+	tmpl, err := h.db.GetTemplateByID(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Errorf("template %s not found: %v", id, err)
+		c.JSON(http.StatusNotFound, StandardResponse{false, "template not found", nil})
+		return
+	}
+
+	cp, ok := h.templateTarget(c, input)
+	if !ok {
+		return
+	}
+	if cp.UserID != tmpl.UserID {
+		c.JSON(http.StatusNotFound, StandardResponse{false, "template not found", nil})
+		return
+	}
+	if cp.Type != tmpl.Channel {
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "template channel " + tmpl.Channel + " does not match contact point type " + cp.Type, nil})
+		return
+	}
+
+	if err := h.setTemplate(c, input, &tmpl.ID); err != nil {
+		h.logger.Errorf("failed to attach template %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, StandardResponse{false, "could not attach template", nil})
+		return
+	}
+
+	h.logger.Infof("attached template %s", id)
+	c.JSON(http.StatusOK, StandardResponse{true, "template attached", tmpl})
+}
+
+// DetachTemplate returns a contact point, or a policy, to the built-in format
+func (h *Handler) DetachTemplate(c *gin.Context) {
+	var input models.TemplateAttachment
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Errorf("invalid detach template payload: %v", err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "invalid request body", nil})
+		return
+	}
+
+	if _, ok := h.templateTarget(c, input); !ok {
+		return
+	}
+	if err := h.setTemplate(c, input, nil); err != nil {
+		h.logger.Errorf("failed to detach template: %v", err)
+		c.JSON(http.StatusInternalServerError, StandardResponse{false, "could not detach template", nil})
+		return
+	}
+
+	h.logger.Infof("detached template from %s%s", input.ContactPointID, input.PolicyID)
+	c.Status(http.StatusNoContent)
+}
+
+// TemplatePreview is a sample alert to render for a contact point, with the template attached to it,
+// or with a stored or unsaved template. Without a contact point the template's channel is rendered
+// with an empty configuration.
+type TemplatePreview struct {
+	Alert          kafka.AlertNotification `json:"alert"`
+	ContactPointID string                  `json:"contact_point_id,omitempty"`
+	TemplateID     string                  `json:"template_id,omitempty"`
+	Template       *models.Template        `json:"template,omitempty"`
+}
+
+// PreviewTemplate renders what an alert would send, without sending it
+func (h *Handler) PreviewTemplate(c *gin.Context) {
+	var input TemplatePreview
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.logger.Errorf("invalid template preview payload: %v", err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "invalid request body", nil})
+		return
+	}
+	if input.TemplateID != "" && input.Template != nil {
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "template_id and template cannot be used together", nil})
+		return
+	}
+
+	var tmpl *models.Template
+	if input.TemplateID != "" {
+		stored, err := h.db.GetTemplateByID(c.Request.Context(), input.TemplateID)
+		if err != nil {
+			h.logger.Errorf("template %s not found: %v", input.TemplateID, err)
+			c.JSON(http.StatusNotFound, StandardResponse{false, "template not found", nil})
+			return
+		}
+		tmpl = &stored
+	} else if input.Template != nil {
+		if err := h.svc.ValidateTemplate(*input.Template); err != nil {
+			h.logger.Errorf("invalid template: %v", err)
+			c.JSON(http.StatusBadRequest, StandardResponse{false, err.Error(), nil})
+			return
+		}
+		tmpl = input.Template
+	}
+
+	var cp models.ContactPoint
+	switch {
+	case input.ContactPointID != "":
+		var err error
+		cp, err = h.db.GetContactPointByID(c.Request.Context(), input.ContactPointID)
+		if err != nil {
+			h.logger.Errorf("contact point %s not found: %v", input.ContactPointID, err)
+			c.JSON(http.StatusNotFound, StandardResponse{false, "contact point not found", nil})
+			return
+		}
+		if input.TemplateID != "" && cp.UserID != tmpl.UserID {
+			c.JSON(http.StatusNotFound, StandardResponse{false, "template not found", nil})
+			return
+		}
+	case tmpl != nil:
+		cp = models.ContactPoint{UserID: tmpl.UserID, Type: tmpl.Channel, Configuration: map[string]interface{}{}}
+	default:
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "contact_point_id, template_id or template is required", nil})
+		return
+	}
+
+	task := input.Alert.Task()
+	if task.RequestID == "" {
+		task.RequestID = uuid.NewString()
+	}
+	if task.RecipientID == 0 {
+		task.RecipientID = cp.UserID
+	}
+
+	rendered, err := h.svc.Preview(c.Request.Context(), task, cp, tmpl)
+	if err != nil {
+		h.logger.Errorf("failed to render preview for %s: %v", cp.Type, err)
+		c.JSON(http.StatusBadRequest, StandardResponse{false, err.Error(), nil})
+		return
+	}
+
+	h.logger.Infof("rendered %s preview for alert %s", cp.Type, task.RequestID)
+	c.JSON(http.StatusOK, StandardResponse{true, "notification preview", rendered})
+}
+
+// templateTarget loads the contact point a template is attached to or detached from,
+// which for a policy is its contact point. It writes the error response when it fails.
+func (h *Handler) templateTarget(c *gin.Context, input models.TemplateAttachment) (models.ContactPoint, bool) {
+	if (input.ContactPointID == "") == (input.PolicyID == "") {
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "exactly one of contact_point_id and policy_id is required", nil})
+		return models.ContactPoint{}, false
+	}
+	if input.ContactPointID != "" {
+		cp, err := h.db.GetContactPointByID(c.Request.Context(), input.ContactPointID)
+		if err != nil {
+			h.logger.Errorf("contact point %s not found: %v", input.ContactPointID, err)
+			c.JSON(http.StatusNotFound, StandardResponse{false, "contact point not found", nil})
+			return models.ContactPoint{}, false
+		}
+		return cp, true
+	}
+	pol, err := h.db.GetPolicyByID(c.Request.Context(), input.PolicyID)
+	if err != nil {
+		h.logger.Errorf("policy %s not found: %v", input.PolicyID, err)
+		c.JSON(http.StatusNotFound, StandardResponse{false, "policy not found", nil})
+		return models.ContactPoint{}, false
+	}
+	if pol.ContactPoint == nil {
+		c.JSON(http.StatusBadRequest, StandardResponse{false, "policy has no active contact point", nil})
+		return models.ContactPoint{}, false
+	}
+	return *pol.ContactPoint, true
+}
+
+// setTemplate stores templateID, or clears it when nil, on the contact point or policy of input.
+func (h *Handler) setTemplate(c *gin.Context, input models.TemplateAttachment, templateID *[16]byte) error {
+	if input.ContactPointID != "" {
+		id, err := uuid.Parse(input.ContactPointID)
+		if err != nil {
+			return err
+		}
+		return h.db.SetContactPointTemplate(c.Request.Context(), id, templateID)
+	}
+	id, err := uuid.Parse(input.PolicyID)
+	if err != nil {
+		return err
+	}
+	return h.db.SetPolicyTemplate(c.Request.Context(), id, templateID)
+}
+
 // parseQueryInt is a helper to read integer query params with default
 func parseQueryInt(c *gin.Context, key string, def int) int {
 	if v := c.DefaultQuery(key, ""); v != "" {
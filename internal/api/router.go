@@ -95,6 +95,74 @@ func NewRouter(logger *logging.Logger, cfg config.Config, handler *Handler) *gin
 		}))
 	}
 
+	// Web Push subscription routes
+	push := rApi.Group("/webpush")
+	{
+		push.GET("/vapid-public-key", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.GetVAPIDPublicKey(c)
+		}))
+		push.POST("/subscribe", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.SubscribeWebPush(c)
+		}))
+		push.POST("/unsubscribe", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.UnsubscribeWebPush(c)
+		}))
+	}
+
+	// Telegram routes
+	tg := rApi.Group("/telegram")
+	{
+		tg.POST("/link", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.CreateTelegramLink(c)
+		}))
+		// Bot updates (button presses, /start), when TELEGRAM_UPDATES_MODE=webhook
+		tg.POST("/webhook/:bot_id", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.TelegramWebhook(c)
+		}))
+	}
+
+	// Message templates routes
+	tpl := rApi.Group("/templates")
+	{
+		tpl.POST("/create", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.CreateTemplate(c)
+		}))
+		tpl.POST("/detach", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.DetachTemplate(c)
+		}))
+		tpl.POST("/preview", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.PreviewTemplate(c)
+		}))
+		tpl.GET("/:id", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.GetTemplate(c)
+		}))
+		tpl.GET("/user/:user_id", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.GetTemplatesByUserID(c)
+		}))
+		tpl.PUT("/:id", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.UpdateTemplate(c)
+		}))
+		tpl.DELETE("/:id", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.DeleteTemplate(c)
+		}))
+		tpl.POST("/:id/attach", handlerWrapper(logger, func(c *gin.Context) {
+			h := ctxHandler(c)
+			h.AttachTemplate(c)
+		}))
+	}
+
 	// WebSocket route for real-time notifications
 	rApi.GET("/ws", handlerWrapper(logger, func(c *gin.Context) {
 		h := ctxHandler(c)
@@ -5,6 +5,7 @@ import (
 	"github.com/joho/godotenv"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config holds application configuration loaded from environment.
@@ -18,11 +19,65 @@ type Config struct {
 		DSN string
 	}
 	Email struct {
-		SMTPServer string
-		SMTPPort   int
-		Username   string
-		Password   string
-		FromName   string
+		Transport      string // smtp, sendgrid, mailgun or ses
+		FromAddress    string // envelope and From address, defaults to Username
+		SMTPServer     string
+		SMTPPort       int
+		Username       string
+		Password       string
+		FromName       string
+		Security       string // auto, starttls, tls or none
+		AuthMechanism  string // plain, login, cram-md5, xoauth2 or none
+		OAuth2Token    string
+		CAFile         string
+		ConnectTimeout int    // seconds
+		LogoPath       string // image embedded inline as cid:logo, optional
+		PoolSize       int    // open SMTP connections kept for reuse
+		IdleTimeout    int    // seconds an idle SMTP connection is kept
+		DKIMDomain     string // d= tag, signing is enabled when domain, selector and key path are set
+		DKIMSelector   string // s= tag
+		DKIMKeyPath    string // PEM RSA or Ed25519 private key
+
+		// HTTP mail API transports
+		APIKey             string // SendGrid or Mailgun API key
+		APIBaseURL         string // defaults to the provider's public endpoint
+		MailgunDomain      string
+		SESRegion          string
+		SESAccessKeyID     string
+		SESSecretAccessKey string
+		SESSessionToken    string
+	}
+	SMS struct {
+		GatewayURL  string
+		AccountSID  string
+		AuthToken   string
+		FromNumber  string
+		MaxSegments int
+	}
+	PagerDuty struct {
+		EventsURL string
+	}
+	WebPush struct {
+		VAPIDPublicKey  string
+		VAPIDPrivateKey string
+		Subject         string
+		TTL             int
+	}
+	Telegram struct {
+		UpdatesMode  string // how bots receive button presses: polling, webhook or off
+		WebhookURL   string // public URL of the /telegram/webhook route, for webhook mode
+		BotToken     string // service bot, used by contact points without their own bot_token
+		LinkTokenTTL int    // seconds a /start link token stays valid
+	}
+	Matrix struct {
+		HomeserverURL string
+		AccessToken   string
+	}
+	Zalo struct {
+		APIBaseURL   string // Zalo OA OpenAPI, overridable for local testing
+		OAuthBaseURL string // Zalo OAuth server that refreshes access tokens
+		AppID        string // default app_id for contact points that omit it
+		AppSecret    string // default app_secret for contact points that omit it
 	}
 	API struct {
 		Port     string
@@ -61,6 +116,8 @@ func Load() (Config, error) {
 	cfg.DB.DSN = os.Getenv("DB_DSN")
 
 	// Email settings
+	cfg.Email.Transport = strings.ToLower(os.Getenv("EMAIL_TRANSPORT"))
+	cfg.Email.FromAddress = os.Getenv("EMAIL_FROM_ADDRESS")
 	cfg.Email.SMTPServer = os.Getenv("EMAIL_SMTP_SERVER")
 	if p, err := strconv.Atoi(os.Getenv("EMAIL_SMTP_PORT")); err == nil {
 		cfg.Email.SMTPPort = p
@@ -68,6 +125,68 @@ func Load() (Config, error) {
 	cfg.Email.Username = os.Getenv("EMAIL_USERNAME")
 	cfg.Email.Password = os.Getenv("EMAIL_PASSWORD")
 	cfg.Email.FromName = os.Getenv("EMAIL_FROM_NAME")
+	cfg.Email.Security = strings.ToLower(os.Getenv("EMAIL_SMTP_SECURITY"))
+	cfg.Email.AuthMechanism = strings.ToLower(os.Getenv("EMAIL_SMTP_AUTH"))
+	cfg.Email.OAuth2Token = os.Getenv("EMAIL_OAUTH2_TOKEN")
+	cfg.Email.CAFile = os.Getenv("EMAIL_SMTP_CA_FILE")
+	if t, err := strconv.Atoi(os.Getenv("EMAIL_SMTP_TIMEOUT")); err == nil {
+		cfg.Email.ConnectTimeout = t
+	}
+	cfg.Email.LogoPath = os.Getenv("EMAIL_LOGO_PATH")
+	if n, err := strconv.Atoi(os.Getenv("EMAIL_SMTP_POOL_SIZE")); err == nil {
+		cfg.Email.PoolSize = n
+	}
+	if t, err := strconv.Atoi(os.Getenv("EMAIL_SMTP_IDLE_TIMEOUT")); err == nil {
+		cfg.Email.IdleTimeout = t
+	}
+	cfg.Email.DKIMDomain = os.Getenv("EMAIL_DKIM_DOMAIN")
+	cfg.Email.DKIMSelector = os.Getenv("EMAIL_DKIM_SELECTOR")
+	cfg.Email.DKIMKeyPath = os.Getenv("EMAIL_DKIM_PRIVATE_KEY_PATH")
+	cfg.Email.APIKey = os.Getenv("EMAIL_API_KEY")
+	cfg.Email.APIBaseURL = os.Getenv("EMAIL_API_BASE_URL")
+	cfg.Email.MailgunDomain = os.Getenv("EMAIL_MAILGUN_DOMAIN")
+	cfg.Email.SESRegion = os.Getenv("EMAIL_SES_REGION")
+	cfg.Email.SESAccessKeyID = os.Getenv("EMAIL_SES_ACCESS_KEY_ID")
+	cfg.Email.SESSecretAccessKey = os.Getenv("EMAIL_SES_SECRET_ACCESS_KEY")
+	cfg.Email.SESSessionToken = os.Getenv("EMAIL_SES_SESSION_TOKEN")
+
+	// SMS gateway settings
+	cfg.SMS.GatewayURL = os.Getenv("SMS_GATEWAY_URL")
+	cfg.SMS.AccountSID = os.Getenv("SMS_ACCOUNT_SID")
+	cfg.SMS.AuthToken = os.Getenv("SMS_AUTH_TOKEN")
+	cfg.SMS.FromNumber = os.Getenv("SMS_FROM_NUMBER")
+	if ms, err := strconv.Atoi(os.Getenv("SMS_MAX_SEGMENTS")); err == nil {
+		cfg.SMS.MaxSegments = ms
+	}
+
+	// PagerDuty settings
+	cfg.PagerDuty.EventsURL = os.Getenv("PAGERDUTY_EVENTS_URL")
+
+	// Web Push (VAPID) settings
+	cfg.WebPush.VAPIDPublicKey = os.Getenv("WEBPUSH_VAPID_PUBLIC_KEY")
+	cfg.WebPush.VAPIDPrivateKey = os.Getenv("WEBPUSH_VAPID_PRIVATE_KEY")
+	cfg.WebPush.Subject = os.Getenv("WEBPUSH_SUBJECT")
+	if ttl, err := strconv.Atoi(os.Getenv("WEBPUSH_TTL")); err == nil {
+		cfg.WebPush.TTL = ttl
+	}
+
+	// Telegram update delivery
+	cfg.Telegram.UpdatesMode = strings.ToLower(os.Getenv("TELEGRAM_UPDATES_MODE"))
+	cfg.Telegram.WebhookURL = os.Getenv("TELEGRAM_WEBHOOK_URL")
+	cfg.Telegram.BotToken = os.Getenv("TELEGRAM_BOT_TOKEN")
+	if ttl, err := strconv.Atoi(os.Getenv("TELEGRAM_LINK_TOKEN_TTL")); err == nil {
+		cfg.Telegram.LinkTokenTTL = ttl
+	}
+
+	// Matrix defaults for contact points that omit them
+	cfg.Matrix.HomeserverURL = os.Getenv("MATRIX_HOMESERVER_URL")
+	cfg.Matrix.AccessToken = os.Getenv("MATRIX_ACCESS_TOKEN")
+
+	// Zalo Official Account settings
+	cfg.Zalo.APIBaseURL = os.Getenv("ZALO_API_BASE_URL")
+	cfg.Zalo.OAuthBaseURL = os.Getenv("ZALO_OAUTH_BASE_URL")
+	cfg.Zalo.AppID = os.Getenv("ZALO_APP_ID")
+	cfg.Zalo.AppSecret = os.Getenv("ZALO_APP_SECRET")
 
 	// API settings
 	cfg.API.Port = os.Getenv("API_PORT")
@@ -121,6 +240,68 @@ func Load() (Config, error) {
 	if cfg.Notification.MaxWorkers == 0 {
 		cfg.Notification.MaxWorkers = 10
 	}
+	if cfg.Email.Transport == "" {
+		cfg.Email.Transport = "smtp"
+	}
+	if cfg.Email.FromAddress == "" {
+		cfg.Email.FromAddress = cfg.Email.Username
+	}
+	if cfg.Email.APIBaseURL == "" {
+		switch cfg.Email.Transport {
+		case "sendgrid":
+			cfg.Email.APIBaseURL = "https://api.sendgrid.com"
+		case "mailgun":
+			cfg.Email.APIBaseURL = "https://api.mailgun.net"
+		case "ses":
+			cfg.Email.APIBaseURL = fmt.Sprintf("https://email.%s.amazonaws.com", cfg.Email.SESRegion)
+		}
+	}
+	if cfg.Email.Security == "" {
+		// Port 465 is implicit TLS; elsewhere upgrade with STARTTLS when offered
+		cfg.Email.Security = "auto"
+		if cfg.Email.SMTPPort == 465 {
+			cfg.Email.Security = "tls"
+		}
+	}
+	if cfg.Email.AuthMechanism == "" {
+		cfg.Email.AuthMechanism = "plain"
+	}
+	if cfg.Email.ConnectTimeout == 0 {
+		cfg.Email.ConnectTimeout = 10
+	}
+	if cfg.Email.PoolSize <= 0 {
+		cfg.Email.PoolSize = 4
+	}
+	if cfg.Email.IdleTimeout <= 0 {
+		cfg.Email.IdleTimeout = 60
+	}
+	if cfg.SMS.GatewayURL == "" {
+		cfg.SMS.GatewayURL = "https://api.twilio.com"
+	}
+	if cfg.SMS.MaxSegments == 0 {
+		cfg.SMS.MaxSegments = 1
+	}
+	if cfg.PagerDuty.EventsURL == "" {
+		cfg.PagerDuty.EventsURL = "https://events.pagerduty.com"
+	}
+	if cfg.WebPush.TTL == 0 {
+		cfg.WebPush.TTL = 86400
+	}
+	if cfg.Telegram.UpdatesMode == "" {
+		cfg.Telegram.UpdatesMode = "polling"
+	}
+	if cfg.Telegram.LinkTokenTTL == 0 {
+		cfg.Telegram.LinkTokenTTL = 900
+	}
+	if cfg.Matrix.HomeserverURL == "" {
+		cfg.Matrix.HomeserverURL = "https://matrix.org"
+	}
+	if cfg.Zalo.APIBaseURL == "" {
+		cfg.Zalo.APIBaseURL = "https://openapi.zalo.me"
+	}
+	if cfg.Zalo.OAuthBaseURL == "" {
+		cfg.Zalo.OAuthBaseURL = "https://oauth.zaloapp.com"
+	}
 	if cfg.RateLimit.WebSocketRateLimiter == 0 {
 		cfg.RateLimit.WebSocketRateLimiter = 5
 	}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AcknowledgeAlert marks every notification of an alert as acknowledged by the given user.
+// An alert that was already acknowledged keeps its first acknowledgement.
+func (d *DB) AcknowledgeAlert(ctx context.Context, requestID [16]byte, by string) error {
+	query := `
+	UPDATE notifications
+	SET acknowledged_at = COALESCE(acknowledged_at, NOW()),
+	    acknowledged_by = COALESCE(acknowledged_by, $2),
+	    updated_at = NOW()
+	WHERE request_id = $1`
+
+	res, err := d.Pool.Exec(ctx, query, uuid.UUID(requestID), by)
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge alert: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("no notifications found for request_id %s", uuid.UUID(requestID))
+	}
+	return nil
+}
+
+// SilenceAlert suppresses further notifications of an alert until the given time.
+func (d *DB) SilenceAlert(ctx context.Context, requestID [16]byte, until time.Time, by string) error {
+	query := `
+	UPDATE notifications
+	SET silenced_until = $2,
+	    silenced_by = $3,
+	    updated_at = NOW()
+	WHERE request_id = $1`
+
+	res, err := d.Pool.Exec(ctx, query, uuid.UUID(requestID), until, by)
+	if err != nil {
+		return fmt.Errorf("failed to silence alert: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("no notifications found for request_id %s", uuid.UUID(requestID))
+	}
+	return nil
+}
+
+// GetAlertSilencedUntil returns when the silence on an alert ends, or the zero time if it is not silenced.
+func (d *DB) GetAlertSilencedUntil(ctx context.Context, requestID [16]byte) (time.Time, error) {
+	query := `
+	SELECT MAX(silenced_until)
+	FROM notifications
+	WHERE request_id = $1`
+
+	var until sql.NullTime
+	if err := d.Pool.QueryRow(ctx, query, uuid.UUID(requestID)).Scan(&until); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get alert silence: %w", err)
+	}
+	return until.Time, nil
+}
@@ -3,10 +3,9 @@ package db
 import (
 	"context"
 	"fmt"
-	"strconv"
+
 	"github.com/google/uuid"
 	"notification-service/internal/models"
-	"github.com/go-telegram/bot"
 )
 
 // CreateContactPoint inserts a new contact point or updates an existing one.
@@ -16,40 +15,6 @@ func (d *DB) CreateContactPoint(ctx context.Context, cp models.ContactPoint) (mo
 		newID := uuid.New()
 		copy(cp.ID[:], newID[:])
 	}
-	// Contact poimt if is telegram
-	if cp.Type == "telegram" {
-		if cp.Configuration == nil {
-			return models.ContactPoint{}, fmt.Errorf("configuration cannot be nil for telegram contact point")
-		}
-		if _, ok := cp.Configuration["chat_id"]; !ok {
-			return models.ContactPoint{}, fmt.Errorf("chat_id is required in configuration for telegram contact point")
-		}
-		if _, ok := cp.Configuration["bot_token"]; !ok {
-			return models.ContactPoint{}, fmt.Errorf("bot_token is required in configuration for telegram contact point")
-		}
-
-		chatIDStr := cp.Configuration["chat_id"].(string)
-		chatID, err := strconv.ParseInt(chatIDStr, 10, 64)
-				// Connect and send a test message to the Telegram bot, cp.Configuration["bot_token"] must be add "bot" before the token
-		botToken := cp.Configuration["bot_token"].(string)
-		// botToken := cp.Configuration["bot_token"].(string)
-
-		// log.Info("Connecting to Telegram bot with token: %s", botToken)
-		fmt.Println("Connecting to Telegram bot with token: %s", botToken)
-		b, err := bot.New(botToken)
-		if err != nil {
-			return models.ContactPoint{}, fmt.Errorf("failed to create Telegram bot: %w", err)
-		}
-
-		_, err = b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID: chatID,
-			Text:   "Test message from Notification Service",
-		})
-		if err != nil {
-			return models.ContactPoint{}, fmt.Errorf("failed to send test message to Telegram bot: %w", err)
-		}
-		fmt.Println("Test message sent to Telegram bot successfully")
-	}
 	query := `
 	INSERT INTO contact_points (
 		id, name, user_id, type, configuration, status, created_at, updated_at
@@ -147,6 +112,43 @@ func (d *DB) GetContactPointsByUserID(ctx context.Context, userID int64) ([]mode
 	return cps, nil
 }
 
+// GetContactPointsByType returns all active contact points of a channel type.
+func (d *DB) GetContactPointsByType(ctx context.Context, cpType string) ([]models.ContactPoint, error) {
+	query := `
+	SELECT id, name, user_id, type, configuration, status, created_at, updated_at
+	FROM contact_points
+	WHERE type = $1 AND status = 'active'`
+
+	rows, err := d.Pool.Query(ctx, query, cpType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact points by type %s: %w", cpType, err)
+	}
+	defer rows.Close()
+
+	var cps []models.ContactPoint
+	for rows.Next() {
+		var cp models.ContactPoint
+		var returnedID uuid.UUID
+		err := rows.Scan(
+			&returnedID,
+			&cp.Name,
+			&cp.UserID,
+			&cp.Type,
+			&cp.Configuration,
+			&cp.Status,
+			&cp.CreatedAt,
+			&cp.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan contact point: %w", err)
+		}
+		copy(cp.ID[:], returnedID[:])
+		cps = append(cps, cp)
+	}
+
+	return cps, nil
+}
+
 // DeleteContactPoint performs a soft-delete by marking status and updating timestamp.
 func (d *DB) DeleteContactPoint(ctx context.Context, idStr string) error {
 	idUUID, err := uuid.Parse(idStr)
@@ -195,3 +197,61 @@ func (d *DB) UpdateContactPoint(ctx context.Context, cp models.ContactPoint) err
 	}
 	return nil
 }
+
+// GetWebPushContactPoint returns the active webpush contact point of a user for a push endpoint.
+func (d *DB) GetWebPushContactPoint(ctx context.Context, userID int, endpoint string) (models.ContactPoint, error) {
+	query := `
+	SELECT id, name, user_id, type, configuration, status, created_at, updated_at
+	FROM contact_points
+	WHERE user_id = $1 AND type = 'webpush' AND configuration->>'endpoint' = $2 AND status = 'active'
+	LIMIT 1`
+
+	var cp models.ContactPoint
+	var returnedID uuid.UUID
+	err := d.Pool.QueryRow(ctx, query, userID, endpoint).Scan(
+		&returnedID,
+		&cp.Name,
+		&cp.UserID,
+		&cp.Type,
+		&cp.Configuration,
+		&cp.Status,
+		&cp.CreatedAt,
+		&cp.UpdatedAt,
+	)
+	if err != nil {
+		return models.ContactPoint{}, fmt.Errorf("failed to get webpush contact point: %w", err)
+	}
+	copy(cp.ID[:], returnedID[:])
+	return cp, nil
+}
+
+// DeleteWebPushContactPoints soft-deletes every webpush contact point of a user for a push endpoint.
+func (d *DB) DeleteWebPushContactPoints(ctx context.Context, userID int, endpoint string) (int64, error) {
+	query := `
+	UPDATE contact_points
+	SET status = 'deleted', updated_at = NOW()
+	WHERE user_id = $1 AND type = 'webpush' AND configuration->>'endpoint' = $2 AND status = 'active'`
+	res, err := d.Pool.Exec(ctx, query, userID, endpoint)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete webpush contact points: %w", err)
+	}
+	return res.RowsAffected(), nil
+}
+
+// MergeContactPointConfig sets the given configuration keys of a contact point, keeping the others.
+func (d *DB) MergeContactPointConfig(ctx context.Context, contactPointID [16]byte, values map[string]interface{}) error {
+	query := `
+	UPDATE contact_points
+	SET configuration = COALESCE(configuration, '{}'::jsonb) || $2::jsonb,
+	    updated_at = NOW()
+	WHERE id = $1`
+
+	res, err := d.Pool.Exec(ctx, query, uuid.UUID(contactPointID), values)
+	if err != nil {
+		return fmt.Errorf("failed to update contact point configuration: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("contact point %s not found", uuid.UUID(contactPointID))
+	}
+	return nil
+}
@@ -1,7 +1,24 @@
 -- Xóa nếu đã tồn tại (theo thứ tự phụ thuộc ngược)
+DROP TABLE IF EXISTS telegram_link_tokens;
+DROP TABLE IF EXISTS message_refs;
 DROP TABLE IF EXISTS notifications;
 DROP TABLE IF EXISTS notification_policy;
 DROP TABLE IF EXISTS contact_points;
+DROP TABLE IF EXISTS templates;
+
+-- Bảng templates (mẫu tin nhắn do người dùng định nghĩa cho từng kênh)
+CREATE TABLE IF NOT EXISTS templates (
+    id UUID PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    html TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
 
 -- Bảng contact_points
 CREATE TABLE IF NOT EXISTS contact_points (
@@ -11,6 +28,9 @@ CREATE TABLE IF NOT EXISTS contact_points (
     type VARCHAR(20) NOT NULL,
     configuration JSONB,
     status VARCHAR(20) NOT NULL DEFAULT 'active',
+    template_id UUID
+    REFERENCES templates(id)
+    ON DELETE SET NULL,
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
     );
@@ -25,6 +45,9 @@ CREATE TABLE IF NOT EXISTS notification_policy (
     status VARCHAR(20) NOT NULL DEFAULT 'active',
     action VARCHAR(50) NOT NULL,
     condition_type VARCHAR(50),
+    template_id UUID
+    REFERENCES templates(id)
+    ON DELETE SET NULL,
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
     );
@@ -56,10 +79,43 @@ CREATE TABLE IF NOT EXISTS notifications (
     threshold_max DOUBLE PRECISION,
     value DOUBLE PRECISION,
 
+    -- Thao tác của người trực (Telegram Acknowledge / Silence)
+    acknowledged_at TIMESTAMPTZ,
+    acknowledged_by VARCHAR(100),
+    silenced_until TIMESTAMPTZ,
+    silenced_by VARCHAR(100),
+
     updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
     );
 
+-- Bảng message_refs (tin nhắn đầu tiên của mỗi alert trên từng contact point, dùng để gom thread)
+CREATE TABLE IF NOT EXISTS message_refs (
+    request_id UUID NOT NULL,
+    contact_point_id UUID NOT NULL
+    REFERENCES contact_points(id)
+    ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    ref TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (request_id, contact_point_id)
+    );
+
+-- Bảng telegram_link_tokens (token dùng một lần cho /start, gắn chat Telegram vào contact point)
+CREATE TABLE IF NOT EXISTS telegram_link_tokens (
+    token VARCHAR(64) PRIMARY KEY,
+    contact_point_id UUID NOT NULL
+    REFERENCES contact_points(id)
+    ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    chat_id BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
+
 -- Indexes tối ưu
+CREATE INDEX idx_templates_user_id
+    ON templates(user_id);
+
 CREATE INDEX idx_contact_points_user_id
     ON contact_points(user_id);
 
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetMessageRef returns the channel message reference stored for an alert and contact point,
+// or an empty string if none was stored.
+func (d *DB) GetMessageRef(ctx context.Context, requestID, contactPointID [16]byte) (string, error) {
+	query := `
+	SELECT ref
+	FROM message_refs
+	WHERE request_id = $1 AND contact_point_id = $2`
+
+	var ref string
+	err := d.Pool.QueryRow(ctx, query, uuid.UUID(requestID), uuid.UUID(contactPointID)).Scan(&ref)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get message ref: %w", err)
+	}
+	return ref, nil
+}
+
+// SaveMessageRef stores the reference of the first message sent for an alert to a contact point.
+// Later calls for the same alert and contact point keep the original reference.
+func (d *DB) SaveMessageRef(ctx context.Context, requestID, contactPointID [16]byte, channel, ref string) error {
+	query := `
+	INSERT INTO message_refs (request_id, contact_point_id, channel, ref)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (request_id, contact_point_id) DO NOTHING`
+
+	if _, err := d.Pool.Exec(ctx, query, uuid.UUID(requestID), uuid.UUID(contactPointID), channel, ref); err != nil {
+		return fmt.Errorf("failed to save message ref: %w", err)
+	}
+	return nil
+}
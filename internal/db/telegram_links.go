@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"notification-service/internal/models"
+)
+
+// CreateTelegramLinkToken stores a one-time /start token for binding a chat to a contact point.
+func (d *DB) CreateTelegramLinkToken(ctx context.Context, token string, contactPointID [16]byte, expiresAt time.Time) error {
+	query := `
+	INSERT INTO telegram_link_tokens (token, contact_point_id, expires_at)
+	VALUES ($1, $2, $3)`
+
+	if _, err := d.Pool.Exec(ctx, query, token, uuid.UUID(contactPointID), expiresAt); err != nil {
+		return fmt.Errorf("failed to create telegram link token: %w", err)
+	}
+	return nil
+}
+
+// BindTelegramChat consumes a link token and makes the chat the only one of its contact point,
+// activating it. Unknown, expired and used tokens return the matching models.ErrLinkToken* error.
+func (d *DB) BindTelegramChat(ctx context.Context, token string, chatID int64) ([16]byte, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return [16]byte{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var cpID uuid.UUID
+	var expiresAt time.Time
+	var usedAt *time.Time
+	err = tx.QueryRow(ctx, `
+	SELECT contact_point_id, expires_at, used_at
+	FROM telegram_link_tokens
+	WHERE token = $1
+	FOR UPDATE`, token).Scan(&cpID, &expiresAt, &usedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return [16]byte{}, models.ErrLinkTokenNotFound
+	}
+	if err != nil {
+		return [16]byte{}, fmt.Errorf("failed to get telegram link token: %w", err)
+	}
+	if usedAt != nil {
+		return [16]byte{}, models.ErrLinkTokenUsed
+	}
+	if time.Now().After(expiresAt) {
+		return [16]byte{}, models.ErrLinkTokenExpired
+	}
+
+	if _, err := tx.Exec(ctx, `
+	UPDATE telegram_link_tokens
+	SET used_at = NOW(), chat_id = $2
+	WHERE token = $1`, token, chatID); err != nil {
+		return [16]byte{}, fmt.Errorf("failed to use telegram link token: %w", err)
+	}
+
+	// The bound chat replaces every chat the contact point had, and the forum topic of the old one
+	res, err := tx.Exec(ctx, `
+	UPDATE contact_points
+	SET configuration = (COALESCE(configuration, '{}'::jsonb) - 'chat_ids' - 'message_thread_id') || jsonb_build_object('chat_id', $2::text),
+	    status = 'active',
+	    updated_at = NOW()
+	WHERE id = $1 AND type = 'telegram' AND status IN ('active', 'pending')`, cpID, strconv.FormatInt(chatID, 10))
+	if err != nil {
+		return [16]byte{}, fmt.Errorf("failed to bind telegram chat: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return [16]byte{}, models.ErrLinkTokenNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return [16]byte{}, fmt.Errorf("failed to commit telegram chat binding: %w", err)
+	}
+	return cpID, nil
+}
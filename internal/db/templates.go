@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"notification-service/internal/models"
+)
+
+// templateColumns lists the columns scanned by scanTemplate, in order.
+const templateColumns = `t.id, t.user_id, t.name, t.channel, t.subject, t.body, t.html, t.status, t.created_at, t.updated_at`
+
+// scanTemplate reads one row selected with templateColumns.
+func scanTemplate(row pgx.Row) (models.Template, error) {
+	var t models.Template
+	var returnedID uuid.UUID
+	err := row.Scan(
+		&returnedID,
+		&t.UserID,
+		&t.Name,
+		&t.Channel,
+		&t.Subject,
+		&t.Body,
+		&t.HTML,
+		&t.Status,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return models.Template{}, err
+	}
+	copy(t.ID[:], returnedID[:])
+	return t, nil
+}
+
+// CreateTemplate inserts a new message template.
+func (d *DB) CreateTemplate(ctx context.Context, t models.Template) (models.Template, error) {
+	// Ensure ID is set
+	if t.ID == [16]byte{} {
+		newID := uuid.New()
+		copy(t.ID[:], newID[:])
+	}
+	query := `
+	INSERT INTO templates (
+		id, user_id, name, channel, subject, body, html, status, created_at, updated_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+	RETURNING created_at, updated_at`
+
+	err := d.Pool.QueryRow(ctx, query,
+		uuid.UUID(t.ID),
+		t.UserID,
+		t.Name,
+		t.Channel,
+		t.Subject,
+		t.Body,
+		t.HTML,
+		t.Status,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return models.Template{}, fmt.Errorf("failed to create template: %w", err)
+	}
+	return t, nil
+}
+
+// GetTemplateByID retrieves an active template by its UUID string.
+func (d *DB) GetTemplateByID(ctx context.Context, idStr string) (models.Template, error) {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return models.Template{}, fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	query := `
+	SELECT ` + templateColumns + `
+	FROM templates t
+	WHERE t.id = $1 AND t.status = 'active'`
+
+	t, err := scanTemplate(d.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		return models.Template{}, fmt.Errorf("failed to get template: %w", err)
+	}
+	return t, nil
+}
+
+// GetTemplatesByUserID returns all active templates of a user.
+func (d *DB) GetTemplatesByUserID(ctx context.Context, userID int64) ([]models.Template, error) {
+	query := `
+	SELECT ` + templateColumns + `
+	FROM templates t
+	WHERE t.user_id = $1 AND t.status = 'active'
+	ORDER BY t.created_at`
+
+	rows, err := d.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get templates by user_id %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var list []models.Template
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan template: %w", err)
+		}
+		list = append(list, t)
+	}
+	return list, nil
+}
+
+// UpdateTemplate updates the name and sources of an active template.
+func (d *DB) UpdateTemplate(ctx context.Context, t models.Template) error {
+	query := `
+	UPDATE templates
+	SET name = $1,
+	    subject = $2,
+	    body = $3,
+	    html = $4,
+	    updated_at = NOW()
+	WHERE id = $5 AND status = 'active'`
+
+	_, err := d.Pool.Exec(ctx, query, t.Name, t.Subject, t.Body, t.HTML, uuid.UUID(t.ID))
+	if err != nil {
+		return fmt.Errorf("failed to update template: %w", err)
+	}
+	return nil
+}
+
+// DeleteTemplate soft-deletes a template and detaches it, so that its
+// contact points and policies go back to the built-in format.
+func (d *DB) DeleteTemplate(ctx context.Context, idStr string) error {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	queries := []string{
+		`UPDATE templates SET status = 'deleted', updated_at = NOW() WHERE id = $1`,
+		`UPDATE contact_points SET template_id = NULL WHERE template_id = $1`,
+		`UPDATE notification_policy SET template_id = NULL WHERE template_id = $1`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(ctx, query, id); err != nil {
+			return fmt.Errorf("failed to delete template: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit template deletion: %w", err)
+	}
+	return nil
+}
+
+// SetContactPointTemplate attaches a template to a contact point, or detaches its template when templateID is nil.
+func (d *DB) SetContactPointTemplate(ctx context.Context, contactPointID [16]byte, templateID *[16]byte) error {
+	query := `
+	UPDATE contact_points
+	SET template_id = $1, updated_at = NOW()
+	WHERE id = $2 AND status = 'active'`
+
+	if _, err := d.Pool.Exec(ctx, query, nullableUUID(templateID), uuid.UUID(contactPointID)); err != nil {
+		return fmt.Errorf("failed to set contact point template: %w", err)
+	}
+	return nil
+}
+
+// SetPolicyTemplate attaches a template to a policy, or detaches its template when templateID is nil.
+func (d *DB) SetPolicyTemplate(ctx context.Context, policyID [16]byte, templateID *[16]byte) error {
+	query := `
+	UPDATE notification_policy
+	SET template_id = $1, updated_at = NOW()
+	WHERE id = $2 AND status = 'active'`
+
+	if _, err := d.Pool.Exec(ctx, query, nullableUUID(templateID), uuid.UUID(policyID)); err != nil {
+		return fmt.Errorf("failed to set policy template: %w", err)
+	}
+	return nil
+}
+
+// GetDispatchTemplate returns the template to render a policy's notification with:
+// the one attached to the policy, otherwise the one attached to its contact point,
+// provided it was written for the given channel. It returns nil if there is none.
+func (d *DB) GetDispatchTemplate(ctx context.Context, policyID, contactPointID [16]byte, channel string) (*models.Template, error) {
+	query := `
+	SELECT ` + templateColumns + `
+	FROM templates t
+	LEFT JOIN notification_policy p ON p.id = $1 AND p.template_id = t.id
+	LEFT JOIN contact_points cp ON cp.id = $2 AND cp.template_id = t.id
+	WHERE t.status = 'active' AND t.channel = $3 AND (p.id IS NOT NULL OR cp.id IS NOT NULL)
+	ORDER BY p.id IS NULL
+	LIMIT 1`
+
+	t, err := scanTemplate(d.Pool.QueryRow(ctx, query, uuid.UUID(policyID), uuid.UUID(contactPointID), channel))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dispatch template: %w", err)
+	}
+	return &t, nil
+}
+
+// nullableUUID converts an optional ID into a query argument, NULL when id is nil.
+func nullableUUID(id *[16]byte) interface{} {
+	if id == nil {
+		return nil
+	}
+	return uuid.UUID(*id)
+}
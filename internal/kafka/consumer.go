@@ -59,6 +59,28 @@ func (a *AlertNotification) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Task converts the alert into the task the service dispatches.
+func (a AlertNotification) Task() models.Task {
+	return models.Task{
+		RequestID:    a.AlertID,
+		Subject:      a.AlertName,
+		Body:         a.Message,
+		RecipientID:  a.UserID,
+		Severity:     a.Severity,
+		TypeMessage:  a.TypeMessage,
+		Topic:        "alert_notification",
+		Timestamp:    a.Timestamp,
+		StationID:    a.StationID,
+		MetricID:     a.MetricID,
+		MetricName:   a.MetricName,
+		Operator:     a.Operator,
+		Threshold:    a.Threshold,
+		ThresholdMin: a.ThresholdMin,
+		ThresholdMax: a.ThresholdMax,
+		Value:        a.Value,
+	}
+}
+
 // Consumer reads AlertNotification messages and enqueues tasks.
 type Consumer struct {
 	consumerGroup sarama.ConsumerGroup
@@ -78,8 +100,6 @@ type Service interface {
 
 // NewConsumer constructs a new Consumer.
 func NewConsumer(brokers []string, topic, groupID string, svc Service) (*Consumer, error) {
-	ctx, cancel := context.WithCancel(context.Background())
-
 	// Configure consumer group
 	config := sarama.NewConfig()
 	config.Consumer.Offsets.Initial = sarama.OffsetNewest
@@ -95,6 +115,7 @@ func NewConsumer(brokers []string, topic, groupID string, svc Service) (*Consume
 		return nil, fmt.Errorf("failed to create consumer group: %w", err)
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	svc.Logger().Infof("Starting consumer for topic %s with groupID %s", topic, groupID)
 	svc.Logger().Debugf("Consumer group session timeout: %v, heartbeat interval: %v", config.Consumer.Group.Session.Timeout, config.Consumer.Group.Heartbeat.Interval)
 	return &Consumer{
@@ -175,24 +196,7 @@ func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim saram
 		c.logger.Debugf("deduplication took %v", time.Since(t3))
 
 		t4 := time.Now()
-		task := models.Task{
-			RequestID:    alert.AlertID,
-			Subject:      alert.AlertName,
-			Body:         alert.Message,
-			RecipientID:  alert.UserID,
-			Severity:     alert.Severity,
-			TypeMessage:  alert.TypeMessage,
-			Topic:        "alert_notification",
-			Timestamp:    alert.Timestamp,
-			StationID:    alert.StationID,
-			MetricID:     alert.MetricID,
-			MetricName:   alert.MetricName,
-			Operator:     alert.Operator,
-			Threshold:    alert.Threshold,
-			ThresholdMin: alert.ThresholdMin,
-			ThresholdMax: alert.ThresholdMax,
-			Value:        alert.Value,
-		}
+		task := alert.Task()
 		c.svc.QueueTask(task)
 		c.logger.Debugf("queue task took %v", time.Since(t4))
 		c.logger.Infof("Task queued for alert %s", alert.AlertID)
@@ -28,6 +28,7 @@ type Notification struct {
 	Body                 string        `json:"body,omitempty"`
 	NotificationPolicyID [16]byte      `json:"notification_policy_id,omitempty"`
 	Silenced             int           `json:"silenced,omitempty"`
+	Severity             int           `json:"severity,omitempty"` // Alert severity, not stored in DB
 	Status               string        `json:"status,omitempty"`
 	DeliveryMethod       string        `json:"delivery_method,omitempty"`
 	RecipientID          int           `json:"recipient_id,omitempty"`
@@ -36,6 +37,7 @@ type Notification struct {
 	Context              AlertContext  `json:"context,omitempty"`
 	Policy               *Policy       `json:"policy,omitempty"`        // Added for response, not stored in DB
 	ContactPoint         *ContactPoint `json:"contact_point,omitempty"` // Added for response, not stored in DB
+	Template             *Template     `json:"-"`                       // User-defined format resolved at dispatch, not stored in DB
 }
 
 // MarshalJSON customizes JSON serialization for Notification to return UUIDs as strings.
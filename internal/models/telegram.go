@@ -0,0 +1,30 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Reasons a Telegram /start link token is refused.
+var (
+	ErrLinkTokenNotFound = errors.New("link token not found")
+	ErrLinkTokenExpired  = errors.New("link token expired")
+	ErrLinkTokenUsed     = errors.New("link token already used")
+)
+
+// TelegramLinkCreate represents the input structure for issuing a Telegram chat link.
+// Without ContactPointID a new telegram contact point is created, pending until the chat is bound.
+type TelegramLinkCreate struct {
+	UserID         int    `json:"user_id" binding:"required"`
+	Name           string `json:"name,omitempty"`
+	ContactPointID string `json:"contact_point_id,omitempty"`
+}
+
+// TelegramLink is the one-time link a user opens to bind a Telegram chat to a contact point.
+type TelegramLink struct {
+	ContactPointID string    `json:"contact_point_id"`
+	Token          string    `json:"token"`
+	URL            string    `json:"url"`       // opens a private chat with the bot
+	GroupURL       string    `json:"group_url"` // adds the bot to a group of the user's choice
+	ExpiresAt      time.Time `json:"expires_at"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Template is a user-defined message format for one channel. Subject and Body are
+// Go text/template sources, HTML is an html/template source used by email only.
+type Template struct {
+	ID        [16]byte  `json:"id"`
+	UserID    int       `json:"user_id"`
+	Name      string    `json:"name"`
+	Channel   string    `json:"channel"`
+	Subject   string    `json:"subject,omitempty"`
+	Body      string    `json:"body"`
+	HTML      string    `json:"html,omitempty"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TemplateCreate represents the input structure for creating a new template.
+type TemplateCreate struct {
+	UserID  int    `json:"user_id" binding:"required"`
+	Name    string `json:"name" binding:"required"`
+	Channel string `json:"channel" binding:"required"`
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body" binding:"required"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// TemplateUpdate represents the input structure for updating an existing template.
+// Subject and HTML are pointers so that they can be cleared with an empty string.
+type TemplateUpdate struct {
+	Name    string  `json:"name,omitempty"`
+	Subject *string `json:"subject,omitempty"`
+	Body    string  `json:"body,omitempty"`
+	HTML    *string `json:"html,omitempty"`
+}
+
+// TemplateAttachment names the contact point or policy a template is attached to or detached from.
+type TemplateAttachment struct {
+	ContactPointID string `json:"contact_point_id,omitempty"`
+	PolicyID       string `json:"policy_id,omitempty"`
+}
+
+// MarshalJSON customizes JSON serialization for Template to return the UUID as a string.
+func (t Template) MarshalJSON() ([]byte, error) {
+	type Alias Template
+	return json.Marshal(&struct {
+		ID string `json:"id"`
+		*Alias
+	}{
+		ID:    uuid.UUID(t.ID).String(),
+		Alias: (*Alias)(&t),
+	})
+}
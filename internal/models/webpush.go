@@ -0,0 +1,23 @@
+package models
+
+// WebPushSubscription mirrors the browser PushSubscription.toJSON() shape.
+type WebPushSubscription struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys" binding:"required"`
+}
+
+// WebPushSubscribe represents the input structure for registering a browser subscription.
+type WebPushSubscribe struct {
+	UserID       int                 `json:"user_id" binding:"required"`
+	Name         string              `json:"name,omitempty"`
+	Subscription WebPushSubscription `json:"subscription" binding:"required"`
+}
+
+// WebPushUnsubscribe represents the input structure for removing a browser subscription.
+type WebPushUnsubscribe struct {
+	UserID   int    `json:"user_id" binding:"required"`
+	Endpoint string `json:"endpoint" binding:"required"`
+}
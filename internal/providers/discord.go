@@ -0,0 +1,154 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+)
+
+// Discord embed limits
+const (
+	discordTitleLimit       = 256
+	discordDescriptionLimit = 4096
+	discordFieldValueLimit  = 1024
+)
+
+// discordConfig holds the webhook URL for a Discord contact point.
+type discordConfig struct {
+	WebhookURL string `json:"webhook_url"`
+	Username   string `json:"username"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []discordEmbedField `json:"fields"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+	Footer      *discordEmbedFooter `json:"footer,omitempty"`
+}
+
+type discordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+type discordMessage struct {
+	Username string         `json:"username,omitempty"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+// DiscordProvider delivers notifications as embeds to a Discord webhook.
+type DiscordProvider struct {
+	logger *logging.Logger
+}
+
+// NewDiscordProvider constructs a DiscordProvider
+func NewDiscordProvider(logger *logging.Logger) *DiscordProvider {
+	return &DiscordProvider{logger: logger}
+}
+
+// Name implements Provider
+func (p *DiscordProvider) Name() string {
+	return "discord"
+}
+
+// Capabilities implements Provider
+func (p *DiscordProvider) Capabilities() Capabilities {
+	return Capabilities{RichText: true, MaxLength: discordDescriptionLimit}
+}
+
+// ValidateConfig implements Provider
+func (p *DiscordProvider) ValidateConfig(_ context.Context, cfg map[string]interface{}) error {
+	var dCfg discordConfig
+	if err := decodeConfig(cfg, &dCfg); err != nil {
+		return err
+	}
+	if err := validateURL(dCfg.WebhookURL); err != nil {
+		return fmt.Errorf("discord contact point webhook_url: %w", err)
+	}
+	// Webhook URLs have the form .../api/webhooks/{id}/{token}
+	u, _ := url.Parse(dCfg.WebhookURL)
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 || parts[len(parts)-4] != "api" || parts[len(parts)-3] != "webhooks" {
+		return fmt.Errorf("discord contact point webhook_url must look like .../api/webhooks/{id}/{token}")
+	}
+	return nil
+}
+
+// Send implements Provider
+func (p *DiscordProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	var dCfg discordConfig
+	if err := decodeConfig(cp.Configuration, &dCfg); err != nil {
+		return fmt.Errorf("invalid Discord configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	if dCfg.WebhookURL == "" {
+		return fmt.Errorf("missing webhook_url in Discord configuration for contact point %s", uuid.UUID(cp.ID))
+	}
+
+	r, err := p.Render(ctx, notif, cp)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	return utils.Retry(ctx, p.logger, 3, time.Second, func() error {
+		if _, err := doRequest(ctx, "POST", dCfg.WebhookURL, headers, r.Payload); err != nil {
+			return retryableHTTPError(fmt.Errorf("failed to send Discord embed: %w", err))
+		}
+		p.logger.Infof("Discord embed sent for contact point %s", uuid.UUID(cp.ID))
+		return nil
+	})
+}
+
+// Render implements Provider
+func (p *DiscordProvider) Render(_ context.Context, notif models.Notification, cp models.ContactPoint) (Rendered, error) {
+	var dCfg discordConfig
+	if err := decodeConfig(cp.Configuration, &dCfg); err != nil {
+		return Rendered{}, fmt.Errorf("invalid Discord configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	msg := buildDiscordMessage(notif)
+	msg.Username = dCfg.Username
+	return renderJSON(p.Name(), msg)
+}
+
+// buildDiscordMessage renders a notification as a single colour-coded embed.
+func buildDiscordMessage(notif models.Notification) discordMessage {
+	color, _ := strconv.ParseInt(severityColor(notif), 16, 32)
+
+	var fields []discordEmbedField
+	for _, f := range alertFields(notif.Context) {
+		// Discord rejects the whole message if a field value is blank
+		value := f.Value
+		if strings.TrimSpace(value) == "" {
+			value = "-"
+		}
+		fields = append(fields, discordEmbedField{Name: f.Name, Value: truncate(value, discordFieldValueLimit), Inline: true})
+	}
+
+	embed := discordEmbed{
+		Title:       truncate(alertTitle(notif), discordTitleLimit),
+		Description: truncate(notif.Body, discordDescriptionLimit),
+		Color:       int(color),
+		Fields:      fields,
+	}
+	if !notif.CreatedAt.IsZero() {
+		embed.Timestamp = notif.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	embed.Footer = &discordEmbedFooter{Text: fmt.Sprintf("Alert %s", uuid.UUID(notif.RequestID))}
+
+	return discordMessage{Embeds: []discordEmbed{embed}}
+}
@@ -0,0 +1,26 @@
+package providers
+
+import (
+	"testing"
+
+	"notification-service/internal/models"
+)
+
+func TestDiscordEmbedFieldsAreNeverBlank(t *testing.T) {
+	notif := models.Notification{
+		Subject: "pH out of range",
+		Context: models.AlertContext{StationID: 7, MetricName: "ph", Operator: ""},
+	}
+	msg := buildDiscordMessage(notif)
+	if len(msg.Embeds) != 1 || len(msg.Embeds[0].Fields) == 0 {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	for _, f := range msg.Embeds[0].Fields {
+		if f.Value == "" {
+			t.Errorf("field %q has an empty value", f.Name)
+		}
+		if f.Name == "Operator" && f.Value != "-" {
+			t.Errorf("empty operator rendered as %q", f.Value)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package providers
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"notification-service/internal/config"
+)
+
+// dkimSignedHeaders are signed when present, in this order (RFC 6376 section 5.4)
+var dkimSignedHeaders = []string{
+	"From", "Reply-To", "Subject", "Date", "To", "Cc", "Message-ID",
+	"In-Reply-To", "References", "MIME-Version", "Content-Type",
+}
+
+// dkimSigner signs messages with relaxed/relaxed canonicalization using an RSA or Ed25519 key.
+type dkimSigner struct {
+	domain   string
+	selector string
+	key      crypto.Signer
+	algo     string // rsa-sha256 or ed25519-sha256
+}
+
+// dkimSigners caches signers by key path
+var (
+	dkimSignersMu sync.Mutex
+	dkimSigners   = map[string]*dkimSigner{}
+)
+
+// loadDKIMSigner returns the signer for cfg.Email, or nil when DKIM is not configured.
+func loadDKIMSigner(cfg config.Config) (*dkimSigner, error) {
+	e := cfg.Email
+	if e.DKIMDomain == "" && e.DKIMSelector == "" && e.DKIMKeyPath == "" {
+		return nil, nil
+	}
+	if e.DKIMDomain == "" || e.DKIMSelector == "" || e.DKIMKeyPath == "" {
+		return nil, fmt.Errorf("incomplete DKIM settings: domain, selector and private key path are required")
+	}
+
+	dkimSignersMu.Lock()
+	defer dkimSignersMu.Unlock()
+	if s, ok := dkimSigners[e.DKIMKeyPath]; ok && s.domain == e.DKIMDomain && s.selector == e.DKIMSelector {
+		return s, nil
+	}
+	pemBytes, err := os.ReadFile(e.DKIMKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DKIM private key: %w", err)
+	}
+	key, algo, err := parseDKIMKey(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+	s := &dkimSigner{domain: e.DKIMDomain, selector: e.DKIMSelector, key: key, algo: algo}
+	dkimSigners[e.DKIMKeyPath] = s
+	return s, nil
+}
+
+// parseDKIMKey decodes a PEM private key: PKCS#1 RSA, or PKCS#8 RSA or Ed25519.
+func parseDKIMKey(pemBytes []byte) (crypto.Signer, string, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, "", fmt.Errorf("DKIM private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, "rsa-sha256", nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse DKIM private key: %w", err)
+	}
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		return key, "rsa-sha256", nil
+	case ed25519.PrivateKey:
+		return key, "ed25519-sha256", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported DKIM key type %T", parsed)
+	}
+}
+
+// Sign returns msg with a DKIM-Signature header prepended. msg must use CRLF line endings.
+func (s *dkimSigner) Sign(msg []byte) ([]byte, error) {
+	header, body, ok := bytes.Cut(msg, []byte("\r\n\r\n"))
+	if !ok {
+		return nil, fmt.Errorf("message has no header/body separator")
+	}
+	fields := parseHeaderFields(string(header) + "\r\n")
+
+	bodyHash := sha256.Sum256(dkimRelaxedBody(body))
+
+	var names []string
+	var signed strings.Builder
+	for _, name := range dkimSignedHeaders {
+		if field, ok := lastHeaderField(fields, name); ok {
+			names = append(names, strings.ToLower(name))
+			signed.WriteString(dkimRelaxedHeader(field))
+			signed.WriteString("\r\n")
+		}
+	}
+
+	sigValue := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s; t=%d; h=%s; bh=%s; b=",
+		s.algo, s.domain, s.selector, time.Now().Unix(), strings.Join(names, ":"),
+		base64.StdEncoding.EncodeToString(bodyHash[:]))
+	// The signature header itself is signed with an empty b= and without its trailing CRLF
+	signed.WriteString(dkimRelaxedHeader("DKIM-Signature: " + sigValue))
+
+	digest := sha256.Sum256([]byte(signed.String()))
+	var sig []byte
+	var err error
+	if s.algo == "ed25519-sha256" {
+		// RFC 8463: PureEdDSA over the SHA-256 digest
+		sig, err = s.key.Sign(rand.Reader, digest[:], crypto.Hash(0))
+	} else {
+		sig, err = s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("DKIM signing failed: %w", err)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("DKIM-Signature: " + sigValue + foldBase64(base64.StdEncoding.EncodeToString(sig)) + "\r\n")
+	out.Write(msg)
+	return out.Bytes(), nil
+}
+
+// parseHeaderFields splits a CRLF-terminated header block into fields, keeping folded continuation lines.
+func parseHeaderFields(header string) []string {
+	var fields []string
+	for _, line := range strings.SplitAfter(header, "\r\n") {
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(fields) > 0 {
+			fields[len(fields)-1] += line
+			continue
+		}
+		fields = append(fields, line)
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSuffix(fields[i], "\r\n")
+	}
+	return fields
+}
+
+// lastHeaderField returns the last field with the given name; signers select instances bottom-up.
+func lastHeaderField(fields []string, name string) (string, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if n, _, ok := strings.Cut(fields[i], ":"); ok && strings.EqualFold(strings.TrimSpace(n), name) {
+			return fields[i], true
+		}
+	}
+	return "", false
+}
+
+// dkimRelaxedHeader applies relaxed header canonicalization (RFC 6376 section 3.4.2) to one field.
+func dkimRelaxedHeader(field string) string {
+	name, value, _ := strings.Cut(field, ":")
+	value = strings.ReplaceAll(value, "\r\n", "")
+	return strings.ToLower(strings.TrimSpace(name)) + ":" + strings.TrimSpace(collapseWSP(value))
+}
+
+// dkimRelaxedBody applies relaxed body canonicalization (RFC 6376 section 3.4.4).
+func dkimRelaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(collapseWSP(line), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+// collapseWSP replaces each run of spaces and tabs with a single space.
+func collapseWSP(s string) string {
+	var b strings.Builder
+	inWSP := false
+	for i := 0; i < len(s); i++ {
+		if s[i] == ' ' || s[i] == '\t' {
+			if !inWSP {
+				b.WriteByte(' ')
+			}
+			inWSP = true
+			continue
+		}
+		inWSP = false
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// foldBase64 splits a long base64 value over continuation lines.
+func foldBase64(s string) string {
+	const width = 72
+	var b strings.Builder
+	for len(s) > width {
+		b.WriteString(s[:width] + "\r\n ")
+		s = s[width:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
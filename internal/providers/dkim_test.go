@@ -0,0 +1,132 @@
+package providers
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// dkimTestMessage has folded and padded headers and trailing whitespace and blank lines in the body
+const dkimTestMessage = "From: Alerts <alerts@example.com>\r\n" +
+	"To: ops@example.com\r\n" +
+	"Subject:  pH   out of\r\n\trange \r\n" +
+	"Message-ID: <1@example.com>\r\n" +
+	"X-Unsigned: ignored\r\n" +
+	"\r\n" +
+	"Station 7  \t reports pH 9.1 \r\n" +
+	"\r\n" +
+	"Threshold 8.5\r\n" +
+	"\r\n" +
+	"\r\n"
+
+// dkimTestCanonicalBody is dkimTestMessage's body after relaxed canonicalization
+const dkimTestCanonicalBody = "Station 7 reports pH 9.1\r\n" +
+	"\r\n" +
+	"Threshold 8.5\r\n"
+
+func TestDKIMRelaxedCanonicalizationRFC6376(t *testing.T) {
+	// RFC 6376 section 3.4.5
+	fields := parseHeaderFields("A: X\r\nB : Y\t\r\n\tZ  \r\n")
+	var got []string
+	for _, f := range fields {
+		got = append(got, dkimRelaxedHeader(f))
+	}
+	if want := []string{"a:X", "b:Y Z"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("relaxed headers = %q, want %q", got, want)
+	}
+	if got := string(dkimRelaxedBody([]byte(" C \r\nD \t E\r\n\r\n\r\n"))); got != " C\r\nD E\r\n" {
+		t.Errorf("relaxed body = %q, want %q", got, " C\r\nD E\r\n")
+	}
+	if got := dkimRelaxedBody([]byte("\r\n\r\n")); len(got) != 0 {
+		t.Errorf("relaxed empty body = %q, want empty", got)
+	}
+}
+
+func TestDKIMSignRSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := signDKIMTestMessage(t, &dkimSigner{domain: "example.com", selector: "s1", key: key, algo: "rsa-sha256"})
+	verifyDKIMTestMessage(t, signed, "rsa-sha256", func(digest, sig []byte) bool {
+		return rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest, sig) == nil
+	})
+}
+
+func TestDKIMSignEd25519(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := signDKIMTestMessage(t, &dkimSigner{domain: "example.com", selector: "s1", key: key, algo: "ed25519-sha256"})
+	verifyDKIMTestMessage(t, signed, "ed25519-sha256", func(digest, sig []byte) bool {
+		// RFC 8463: the Ed25519 signature covers the SHA-256 digest
+		return ed25519.Verify(pub, digest, sig)
+	})
+}
+
+func signDKIMTestMessage(t *testing.T, s *dkimSigner) string {
+	t.Helper()
+	signed, err := s.Sign([]byte(dkimTestMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(signed), dkimTestMessage) {
+		t.Fatal("signed message does not end with the original message")
+	}
+	return string(signed)
+}
+
+// verifyDKIMTestMessage checks bh= and b= of the first DKIM-Signature field independently of the signer.
+func verifyDKIMTestMessage(t *testing.T, signed, algo string, verify func(digest, sig []byte) bool) {
+	t.Helper()
+	sigField := strings.TrimPrefix(signed[:len(signed)-len(dkimTestMessage)], "DKIM-Signature:")
+	sigField = strings.TrimSuffix(sigField, "\r\n")
+
+	tags := map[string]string{}
+	for _, tag := range strings.Split(sigField, ";") {
+		name, value, _ := strings.Cut(tag, "=")
+		tags[strings.TrimSpace(name)] = regexp.MustCompile(`\s+`).ReplaceAllString(value, "")
+	}
+	if tags["a"] != algo || tags["c"] != "relaxed/relaxed" || tags["d"] != "example.com" || tags["s"] != "s1" {
+		t.Fatalf("unexpected tags %v", tags)
+	}
+	if tags["h"] != "from:subject:to:message-id" {
+		t.Errorf("h = %s, want from:subject:to:message-id", tags["h"])
+	}
+
+	bodyHash := sha256.Sum256([]byte(dkimTestCanonicalBody))
+	if want := base64.StdEncoding.EncodeToString(bodyHash[:]); tags["bh"] != want {
+		t.Errorf("bh = %s, want %s", tags["bh"], want)
+	}
+
+	// Canonical headers as listed in h=, then the signature field with an empty b= and no CRLF
+	canonical := map[string]string{
+		"from":       "from:Alerts <alerts@example.com>",
+		"to":         "to:ops@example.com",
+		"subject":    "subject:pH out of range",
+		"message-id": "message-id:<1@example.com>",
+	}
+	var input strings.Builder
+	for _, name := range strings.Split(tags["h"], ":") {
+		input.WriteString(canonical[name] + "\r\n")
+	}
+	unsigned := regexp.MustCompile(`b=[^;]*$`).ReplaceAllString(sigField, "b=")
+	unsigned = regexp.MustCompile(`[ \t]+`).ReplaceAllString(strings.ReplaceAll(unsigned, "\r\n", ""), " ")
+	input.WriteString("dkim-signature:" + strings.TrimSpace(unsigned))
+
+	sig, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		t.Fatalf("b= is not base64: %v", err)
+	}
+	digest := sha256.Sum256([]byte(input.String()))
+	if !verify(digest[:], sig) {
+		t.Error("b= does not verify with the public key")
+	}
+}
@@ -3,15 +3,21 @@ package providers
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"net/smtp"
+	"mime"
+	"net/http"
+	"net/mail"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"text/template"
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/time/rate"
 	"notification-service/internal/config"
 	"notification-service/internal/logging"
@@ -19,9 +25,107 @@ import (
 	"notification-service/internal/utils"
 )
 
-// emailConfig holds recipient email address parsed from ContactPoint.Configuration.
+// maxEmailRecipients caps to+cc+bcc for a single contact point
+const maxEmailRecipients = 50
+
+// emailConfig holds recipient email addresses parsed from ContactPoint.Configuration.
+// Email is the original single-recipient field and is treated as one more To address.
 type emailConfig struct {
-	Email string `json:"email"`
+	Email     string   `json:"email,omitempty"`
+	To        []string `json:"to,omitempty"`
+	Cc        []string `json:"cc,omitempty"`
+	Bcc       []string `json:"bcc,omitempty"`
+	ReplyTo   string   `json:"reply_to,omitempty"`
+	AttachCSV bool     `json:"attach_csv,omitempty"` // attach the AlertContext values as CSV
+}
+
+// toAddresses returns Email followed by To, skipping blanks.
+func (ec emailConfig) toAddresses() []string {
+	var to []string
+	if strings.TrimSpace(ec.Email) != "" {
+		to = append(to, ec.Email)
+	}
+	for _, addr := range ec.To {
+		if strings.TrimSpace(addr) != "" {
+			to = append(to, addr)
+		}
+	}
+	return to
+}
+
+// validate checks that there is at least one To address and that every address parses.
+func (ec emailConfig) validate() error {
+	to := ec.toAddresses()
+	if len(to) == 0 {
+		return fmt.Errorf("email or to is required in configuration for email contact point")
+	}
+	if n := len(to) + len(ec.Cc) + len(ec.Bcc); n > maxEmailRecipients {
+		return fmt.Errorf("email contact point has %d recipients, at most %d allowed", n, maxEmailRecipients)
+	}
+	lists := []struct {
+		field string
+		addrs []string
+	}{{"to", to}, {"cc", ec.Cc}, {"bcc", ec.Bcc}}
+	for _, l := range lists {
+		for _, addr := range l.addrs {
+			if _, err := mail.ParseAddress(addr); err != nil {
+				return fmt.Errorf("invalid %s address %q: %w", l.field, addr, err)
+			}
+		}
+	}
+	if ec.ReplyTo != "" {
+		if _, err := mail.ParseAddress(ec.ReplyTo); err != nil {
+			return fmt.Errorf("invalid reply_to address %q: %w", ec.ReplyTo, err)
+		}
+	}
+	return nil
+}
+
+// envelopeRecipients returns the bare, de-duplicated addresses of all To, Cc and Bcc recipients.
+// Addresses must already have passed validate.
+func (ec emailConfig) envelopeRecipients() []string {
+	seen := map[string]bool{}
+	var rcpts []string
+	for _, list := range [][]string{ec.toAddresses(), ec.Cc, ec.Bcc} {
+		for _, addr := range list {
+			a, err := mail.ParseAddress(addr)
+			if err != nil {
+				continue
+			}
+			key := strings.ToLower(a.Address)
+			if !seen[key] {
+				seen[key] = true
+				rcpts = append(rcpts, a.Address)
+			}
+		}
+	}
+	return rcpts
+}
+
+// formatAddressList renders addresses for a To or Cc header, encoding display names.
+func formatAddressList(addrs []string) string {
+	out := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if a, err := mail.ParseAddress(addr); err == nil {
+			out = append(out, a.String())
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// emailLogoCID is the Content-ID of the inline logo referenced by the HTML template
+const emailLogoCID = "logo"
+
+// emailTemplateData is the data passed to the alert_email templates
+type emailTemplateData struct {
+	Subject  string
+	FromName string
+	Username string
+	To       string
+	Body     string
+	Context  models.AlertContext
+	NowYear  int
+	LogoCID  string
 }
 
 // emailLimiter is the global rate limiter for email sending
@@ -41,8 +145,68 @@ func getLimiter(uid int, rps int) *rate.Limiter {
 	return l
 }
 
-// SendEmail sends an alert email using SMTP, populating recipient from ContactPoint configuration.
-func SendEmail(ctx context.Context, notification models.Notification, cp models.ContactPoint, cfg config.Config, logger *logging.Logger) error {
+// EmailProvider delivers notifications over pooled SMTP connections or an HTTP mail API,
+// as selected by EMAIL_TRANSPORT.
+type EmailProvider struct {
+	cfg       config.Config
+	refs      MessageRefStore // Message-IDs of the first email per alert, nil disables threading
+	logger    *logging.Logger
+	transport emailTransport // nil when EMAIL_TRANSPORT is unknown
+}
+
+// NewEmailProvider constructs an EmailProvider
+func NewEmailProvider(cfg config.Config, refs MessageRefStore, logger *logging.Logger) *EmailProvider {
+	return &EmailProvider{cfg: cfg, refs: refs, logger: logger, transport: newEmailTransport(cfg, logger)}
+}
+
+// Name implements Provider
+func (p *EmailProvider) Name() string {
+	return "email"
+}
+
+// Capabilities implements Provider
+func (p *EmailProvider) Capabilities() Capabilities {
+	return Capabilities{RichText: true, Resolve: true}
+}
+
+// ValidateConfig implements Provider
+func (p *EmailProvider) ValidateConfig(_ context.Context, cfg map[string]interface{}) error {
+	var ec emailConfig
+	if err := decodeConfig(cfg, &ec); err != nil {
+		return err
+	}
+	return ec.validate()
+}
+
+// Send implements Provider
+func (p *EmailProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	return p.SendEmail(ctx, notif, cp)
+}
+
+// Close releases the transport, closing pooled SMTP connections
+func (p *EmailProvider) Close() error {
+	if p.transport == nil {
+		return nil
+	}
+	return p.transport.Close()
+}
+
+// Render implements Provider
+func (p *EmailProvider) Render(ctx context.Context, notif models.Notification, cp models.ContactPoint) (Rendered, error) {
+	var ec emailConfig
+	if err := decodeConfig(cp.Configuration, &ec); err != nil {
+		return Rendered{}, fmt.Errorf("invalid email configuration for user %d: %w", notif.RecipientID, err)
+	}
+	email, _, _, err := p.compose(ctx, notif, cp, ec)
+	if err != nil {
+		return Rendered{}, err
+	}
+	return Rendered{Channel: p.Name(), Subject: email.Subject, Text: email.Message.Text, HTML: email.Message.HTML}, nil
+}
+
+// SendEmail sends an alert email through the configured transport, populating recipient from ContactPoint configuration.
+func (p *EmailProvider) SendEmail(ctx context.Context, notification models.Notification, cp models.ContactPoint) error {
+	cfg, logger := p.cfg, p.logger
 
 	// Check rate limit
 	if err := getLimiter(notification.RecipientID, cfg.RateLimit.EmailRateLimiter).Wait(ctx); err != nil {
@@ -58,69 +222,343 @@ func SendEmail(ctx context.Context, notification models.Notification, cp models.
 	if err := json.Unmarshal(configBytes, &ec); err != nil {
 		return fmt.Errorf("invalid email configuration for user %d: %w", notification.RecipientID, err)
 	}
-	if ec.Email == "" {
-		return fmt.Errorf("email not configured for user %d", notification.RecipientID)
+	if err := ec.validate(); err != nil {
+		return fmt.Errorf("email not configured for user %d: %w", notification.RecipientID, err)
 	}
 
-	// Validate SMTP config
-	smtpCfg := cfg.Email
-	if smtpCfg.SMTPServer == "" || smtpCfg.Username == "" || smtpCfg.Password == "" {
-		return fmt.Errorf("incomplete SMTP settings: server/username/password required")
+	// Validate transport config
+	if err := validateEmailTransport(cfg); err != nil {
+		return err
 	}
-	addr := fmt.Sprintf("%s:%d", smtpCfg.SMTPServer, smtpCfg.SMTPPort)
 
-	tmplPath := filepath.Join("template", "alert_email.html")
-	tmplBytes, err := os.ReadFile(tmplPath)
+	email, messageID, threadRef, err := p.compose(ctx, notification, cp, ec)
 	if err != nil {
-		return fmt.Errorf("failed to read email template file: %w", err)
+		return err
 	}
+	raw, err := email.Message.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to build email message: %w", err)
+	}
+	signer, err := loadDKIMSigner(cfg)
+	if err != nil {
+		return err
+	}
+	if signer != nil {
+		if raw, err = signer.Sign(raw); err != nil {
+			return err
+		}
+	}
+	email.Raw = raw
+	rcpts := email.Envelope
 
-	tmpl, err := template.New("email").Parse(string(tmplBytes))
+	// Retry sending email
+	err = utils.Retry(ctx, logger, 3, time.Second, func() error {
+		rejected, err := p.transport.Deliver(ctx, email)
+		if err != nil {
+			err = fmt.Errorf("error sending email to %s: %w", strings.Join(rcpts, ", "), err)
+			if isPermanentEmailError(err) {
+				return utils.Permanent(err)
+			}
+			return err
+		}
+		if len(rejected) > 0 {
+			logger.Warnf("Email for contact point %s not accepted for %s", uuid.UUID(cp.ID), strings.Join(rejected, ", "))
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to parse email template: %w", err)
+		return err
 	}
 
+	if threadRef == "" && p.refs != nil {
+		if err := p.refs.SaveMessageRef(ctx, notification.RequestID, cp.ID, p.Name(), messageID); err != nil {
+			logger.Warnf("Failed to store Message-ID for alert %s: %v", uuid.UUID(notification.RequestID), err)
+		}
+	}
+	return nil
+}
+
+// compose builds the email for a notification, threaded onto the first email of the alert.
+// The recipients in ec are not validated, so that a message can be rendered without them.
+func (p *EmailProvider) compose(ctx context.Context, notification models.Notification, cp models.ContactPoint, ec emailConfig) (email *outgoingEmail, messageID, threadRef string, err error) {
+	smtpCfg := p.cfg.Email
+	to := ec.toAddresses()
+
 	// Prepare template data
-	tmplData := struct {
-		Subject  string
-		FromName string
-		Username string
-		To       string
-		Body     string
-		Context  models.AlertContext
-		NowYear  int
-	}{
+	tmplData := emailTemplateData{
 		Subject:  notification.Subject,
 		FromName: smtpCfg.FromName,
 		Username: smtpCfg.Username,
-		To:       ec.Email,
+		To:       strings.Join(to, ", "),
 		Body:     notification.Body,
 		Context:  notification.Context,
 		NowYear:  time.Now().Year(),
 	}
 
-	var body bytes.Buffer
-	if err := tmpl.Execute(&body, tmplData); err != nil {
-		return fmt.Errorf("failed to execute email template: %w", err)
+	var inline []mimePart
+	if smtpCfg.LogoPath != "" {
+		logo, err := loadInlineImage(smtpCfg.LogoPath, emailLogoCID)
+		if err != nil {
+			return nil, "", "", err
+		}
+		inline = append(inline, logo)
+		tmplData.LogoCID = emailLogoCID
 	}
 
-	msg := bytes.Buffer{}
-	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", notification.Subject))
-	msg.WriteString(fmt.Sprintf("From: %s <%s>\r\n", smtpCfg.FromName, smtpCfg.Username))
-	msg.WriteString(fmt.Sprintf("To: %s\r\n", ec.Email))
-	msg.WriteString("MIME-Version: 1.0\r\n")
-	msg.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
-	msg.WriteString("\r\n")
-	msg.Write(body.Bytes())
+	subjectLine, textBody, htmlBody, err := p.renderEmailContent(notification, tmplData)
+	if err != nil {
+		return nil, "", "", err
+	}
 
-	// Setup authentication
-	auth := smtp.PlainAuth("", smtpCfg.Username, smtpCfg.Password, smtpCfg.SMTPServer)
+	// Follow-ups for the same alert reply to the first email so clients thread them
+	threadRef = p.threadRef(ctx, notification, cp)
+	messageID = newMessageID(notification, messageIDDomain(p.cfg))
+	from := mail.Address{Name: smtpCfg.FromName, Address: smtpCfg.FromAddress}
+	subject := emailSubject(notification.RequestID, subjectLine, threadRef != "")
 
-	// Retry sending email
-	return utils.Retry(logger, 3, time.Second, func() error {
-		if err := smtp.SendMail(addr, auth, smtpCfg.Username, []string{ec.Email}, msg.Bytes()); err != nil {
-			return fmt.Errorf("error sending email to %s: %w", ec.Email, err)
+	msg := mimeMessage{
+		Headers: [][2]string{
+			{"Message-ID", messageID},
+			{"Subject", encodeHeader(subject)},
+			{"From", from.String()},
+			{"To", formatAddressList(to)},
+		},
+		Text:   textBody,
+		HTML:   htmlBody,
+		Inline: inline,
+	}
+	if threadRef != "" {
+		msg.Headers = append(msg.Headers, [2]string{"In-Reply-To", threadRef}, [2]string{"References", threadRef})
+	}
+	if len(ec.Cc) > 0 {
+		msg.Headers = append(msg.Headers, [2]string{"Cc", formatAddressList(ec.Cc)})
+	}
+	if ec.ReplyTo != "" {
+		msg.Headers = append(msg.Headers, [2]string{"Reply-To", formatAddressList([]string{ec.ReplyTo})})
+	}
+	if ec.AttachCSV {
+		csvData, err := alertContextCSV(notification)
+		if err != nil {
+			return nil, "", "", err
 		}
-		return nil
+		msg.Attachments = append(msg.Attachments, mimePart{
+			ContentType: "text/csv",
+			Filename:    fmt.Sprintf("alert-%s.csv", uuid.UUID(notification.RequestID)),
+			Data:        csvData,
+		})
+	}
+
+	email = &outgoingEmail{
+		From:     from,
+		To:       to,
+		Cc:       ec.Cc,
+		Bcc:      ec.Bcc,
+		ReplyTo:  ec.ReplyTo,
+		Subject:  subject,
+		Envelope: ec.envelopeRecipients(),
+		Headers:  [][2]string{{"Message-ID", messageID}},
+		Message:  &msg,
+	}
+	if threadRef != "" {
+		email.Headers = append(email.Headers, [2]string{"In-Reply-To", threadRef}, [2]string{"References", threadRef})
+	}
+	return email, messageID, threadRef, nil
+}
+
+// threadRef returns the Message-ID of the first email sent for the alert to this contact point, if any.
+func (p *EmailProvider) threadRef(ctx context.Context, notif models.Notification, cp models.ContactPoint) string {
+	if p.refs == nil {
+		return ""
+	}
+	ref, err := p.refs.GetMessageRef(ctx, notif.RequestID, cp.ID)
+	if err != nil {
+		p.logger.Warnf("Failed to load Message-ID for alert %s, sending unthreaded: %v", uuid.UUID(notif.RequestID), err)
+		return ""
+	}
+	return ref
+}
+
+// renderEmailContent renders the subject line, plain text and HTML of an alert email
+// with the contact point's template, or with the built-in template files when there
+// is none or it fails.
+func (p *EmailProvider) renderEmailContent(notif models.Notification, data emailTemplateData) (subject, text, htmlBody string, err error) {
+	if t := messageTemplate(notif, p.Name()); t != nil {
+		subject, text, htmlBody, err = renderUserEmail(t, notif)
+		if err == nil {
+			return subject, text, htmlBody, nil
+		}
+		p.logger.Warnf("Template %s failed for alert %s, using the built-in format: %v", uuid.UUID(t.ID), uuid.UUID(notif.RequestID), err)
+	}
+	if htmlBody, err = renderEmailTemplate("alert_email.html", data); err != nil {
+		return "", "", "", err
+	}
+	if text, err = renderEmailTemplate("alert_email.txt", data); err != nil {
+		return "", "", "", err
+	}
+	return notif.Subject, text, htmlBody, nil
+}
+
+// renderUserEmail executes a user-defined email template. Without a subject template the
+// alert's subject is kept, without an HTML template the HTML part repeats the text.
+func renderUserEmail(t *models.Template, notif models.Notification) (subject, text, htmlBody string, err error) {
+	subject = notif.Subject
+	if t.Subject != "" {
+		if subject, err = renderTextTemplate("subject", t.Subject, notif, markupPlain.escape); err != nil {
+			return "", "", "", err
+		}
+		// A header is a single line
+		if subject = strings.Join(strings.Fields(subject), " "); subject == "" {
+			return "", "", "", fmt.Errorf("subject template rendered an empty subject")
+		}
+	}
+	if text, err = renderTextTemplate("body", t.Body, notif, markupPlain.escape); err != nil {
+		return "", "", "", err
+	}
+	if t.HTML == "" {
+		return subject, text, "<p>" + escapeHTML(text) + "</p>", nil
+	}
+	if htmlBody, err = renderHTMLTemplate("html", t.HTML, notif); err != nil {
+		return "", "", "", err
+	}
+	return subject, text, htmlBody, nil
+}
+
+// emailSubject prefixes the subject with a stable per-alert tag, and marks follow-ups as replies.
+func emailSubject(requestID [16]byte, text string, followUp bool) string {
+	subject := fmt.Sprintf("[Alert %s] %s", uuid.UUID(requestID).String()[:8], text)
+	if followUp {
+		subject = "Re: " + subject
+	}
+	return subject
+}
+
+// newMessageID generates a unique Message-ID in the given domain.
+func newMessageID(notif models.Notification, domain string) string {
+	return fmt.Sprintf("<%s.%s@%s>", uuid.UUID(notif.RequestID), uuid.NewString(), domain)
+}
+
+// messageIDDomain returns the domain for Message-IDs: the DKIM signing domain when set,
+// otherwise the domain of the From address, falling back to the host name.
+func messageIDDomain(cfg config.Config) string {
+	if cfg.Email.DKIMDomain != "" {
+		return cfg.Email.DKIMDomain
+	}
+	from := cfg.Email.FromAddress
+	if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+		return from[at+1:]
+	}
+	if host, err := os.Hostname(); err == nil && strings.Contains(host, ".") {
+		return host
+	}
+	return "localhost.localdomain"
+}
+
+// emailTemplates caches parsed templates by name, reloading a template when its file changes.
+var emailTemplates = struct {
+	sync.Mutex
+	m map[string]cachedTemplate
+}{m: map[string]cachedTemplate{}}
+
+type cachedTemplate struct {
+	tmpl    *template.Template
+	modTime time.Time
+}
+
+// inlineImages caches inline images by path, reloading an image when its file changes.
+var inlineImages = struct {
+	sync.Mutex
+	m map[string]cachedImage
+}{m: map[string]cachedImage{}}
+
+type cachedImage struct {
+	part    mimePart
+	modTime time.Time
+}
+
+// renderEmailTemplate executes the named template file from the template directory.
+func renderEmailTemplate(name string, data emailTemplateData) (string, error) {
+	tmpl, err := loadEmailTemplate(name)
+	if err != nil {
+		return "", err
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return "", fmt.Errorf("failed to execute email template %s: %w", name, err)
+	}
+	return body.String(), nil
+}
+
+// loadEmailTemplate returns the parsed template, parsing it on first use or after the file changed.
+func loadEmailTemplate(name string) (*template.Template, error) {
+	path := filepath.Join("template", name)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email template file %s: %w", name, err)
+	}
+
+	emailTemplates.Lock()
+	defer emailTemplates.Unlock()
+	if c, ok := emailTemplates.m[name]; ok && c.modTime.Equal(info.ModTime()) {
+		return c.tmpl, nil
+	}
+	tmplBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email template file %s: %w", name, err)
+	}
+	tmpl, err := template.New(name).Parse(string(tmplBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email template %s: %w", name, err)
+	}
+	emailTemplates.m[name] = cachedTemplate{tmpl: tmpl, modTime: info.ModTime()}
+	return tmpl, nil
+}
+
+// loadInlineImage reads an image to embed in the message under the given Content-ID.
+func loadInlineImage(path, cid string) (mimePart, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return mimePart{}, fmt.Errorf("failed to read inline image: %w", err)
+	}
+
+	inlineImages.Lock()
+	defer inlineImages.Unlock()
+	if c, ok := inlineImages.m[path]; ok && c.modTime.Equal(info.ModTime()) && c.part.ContentID == cid {
+		return c.part, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return mimePart{}, fmt.Errorf("failed to read inline image: %w", err)
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	part := mimePart{ContentType: contentType, Filename: filepath.Base(path), ContentID: cid, Data: data}
+	inlineImages.m[path] = cachedImage{part: part, modTime: info.ModTime()}
+	return part, nil
+}
+
+// alertContextCSV renders the alert context as a two-row CSV: a header and the values.
+func alertContextCSV(notif models.Notification) ([]byte, error) {
+	ac := notif.Context
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"request_id", "type", "station_id", "metric_id", "metric_name", "operator", "threshold", "threshold_min", "threshold_max", "value"})
+	_ = w.Write([]string{
+		uuid.UUID(notif.RequestID).String(),
+		notif.Type,
+		strconv.Itoa(ac.StationID),
+		strconv.Itoa(ac.MetricID),
+		ac.MetricName,
+		ac.Operator,
+		strconv.FormatFloat(ac.Threshold, 'f', -1, 64),
+		strconv.FormatFloat(ac.ThresholdMin, 'f', -1, 64),
+		strconv.FormatFloat(ac.ThresholdMax, 'f', -1, 64),
+		strconv.FormatFloat(ac.Value, 'f', -1, 64),
 	})
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write alert CSV: %w", err)
+	}
+	return buf.Bytes(), nil
 }
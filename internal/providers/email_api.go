@@ -0,0 +1,359 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"notification-service/internal/config"
+	"notification-service/internal/logging"
+)
+
+// Email transports
+const (
+	EmailTransportSMTP     = "smtp"
+	EmailTransportSendGrid = "sendgrid"
+	EmailTransportMailgun  = "mailgun"
+	EmailTransportSES      = "ses"
+)
+
+// outgoingEmail is a rendered email in both structured and raw MIME form,
+// so each transport can use whichever its API accepts.
+type outgoingEmail struct {
+	From     mail.Address
+	To       []string
+	Cc       []string
+	Bcc      []string
+	ReplyTo  string
+	Subject  string
+	Envelope []string    // bare addresses of every recipient
+	Headers  [][2]string // extra headers carried by structured APIs (threading)
+	Message  *mimeMessage
+	Raw      []byte
+}
+
+// emailTransport delivers a rendered email, returning recipients the server refused.
+type emailTransport interface {
+	Deliver(ctx context.Context, email *outgoingEmail) ([]string, error)
+	Close() error
+}
+
+// newEmailTransport returns the transport selected by cfg.Email.Transport, or nil if it is unknown.
+func newEmailTransport(cfg config.Config, logger *logging.Logger) emailTransport {
+	switch cfg.Email.Transport {
+	case EmailTransportSMTP:
+		return newSMTPPool(cfg, logger)
+	case EmailTransportSendGrid:
+		return &sendGridTransport{cfg: cfg}
+	case EmailTransportMailgun:
+		return &mailgunTransport{cfg: cfg}
+	case EmailTransportSES:
+		return &sesTransport{cfg: cfg}
+	default:
+		return nil
+	}
+}
+
+// validateEmailTransport checks the settings required by the configured transport.
+func validateEmailTransport(cfg config.Config) error {
+	e := cfg.Email
+	if e.FromAddress == "" {
+		return fmt.Errorf("incomplete email settings: from address required")
+	}
+	switch e.Transport {
+	case EmailTransportSMTP:
+		return validateSMTPSettings(cfg)
+	case EmailTransportSendGrid:
+		if e.APIKey == "" {
+			return fmt.Errorf("incomplete SendGrid settings: API key required")
+		}
+	case EmailTransportMailgun:
+		if e.APIKey == "" || e.MailgunDomain == "" {
+			return fmt.Errorf("incomplete Mailgun settings: API key and domain required")
+		}
+	case EmailTransportSES:
+		if e.SESRegion == "" || e.SESAccessKeyID == "" || e.SESSecretAccessKey == "" {
+			return fmt.Errorf("incomplete SES settings: region, access key ID and secret access key required")
+		}
+	default:
+		return fmt.Errorf("unknown email transport %q", e.Transport)
+	}
+	return validateURL(e.APIBaseURL)
+}
+
+// isPermanentEmailError reports whether resending cannot fix err: a 5xx SMTP reply
+// or a 4xx API response other than timeouts and rate limiting.
+func isPermanentEmailError(err error) bool {
+	return isPermanentSMTPError(err) || isPermanentHTTPError(err)
+}
+
+// Deliver implements emailTransport
+func (p *smtpPool) Deliver(ctx context.Context, email *outgoingEmail) ([]string, error) {
+	return p.Send(ctx, email.From.Address, email.Envelope, email.Raw)
+}
+
+// sendGridTransport posts to the SendGrid v3 mail/send API. SendGrid does not accept
+// raw MIME, so the message is rebuilt from its parts; SendGrid applies its own DKIM.
+type sendGridTransport struct {
+	cfg config.Config
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To  []sendGridAddress `json:"to"`
+	Cc  []sendGridAddress `json:"cc,omitempty"`
+	Bcc []sendGridAddress `json:"bcc,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"`
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+	ContentID   string `json:"content_id,omitempty"`
+}
+
+type sendGridMessage struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+}
+
+// Deliver implements emailTransport
+func (t *sendGridTransport) Deliver(ctx context.Context, email *outgoingEmail) ([]string, error) {
+	// SendGrid rejects an address that appears more than once across to, cc and bcc
+	seen := map[string]bool{}
+	addrs := func(list []string) []sendGridAddress {
+		var out []sendGridAddress
+		for _, s := range list {
+			a, err := mail.ParseAddress(s)
+			if err != nil || seen[strings.ToLower(a.Address)] {
+				continue
+			}
+			seen[strings.ToLower(a.Address)] = true
+			out = append(out, sendGridAddress{Email: a.Address, Name: a.Name})
+		}
+		return out
+	}
+	msg := sendGridMessage{
+		Personalizations: []sendGridPersonalization{{To: addrs(email.To), Cc: addrs(email.Cc), Bcc: addrs(email.Bcc)}},
+		From:             sendGridAddress{Email: email.From.Address, Name: email.From.Name},
+		Subject:          email.Subject,
+		Content: []sendGridContent{
+			{Type: "text/plain", Value: email.Message.Text},
+			{Type: "text/html", Value: email.Message.HTML},
+		},
+	}
+	if email.ReplyTo != "" {
+		if a, err := mail.ParseAddress(email.ReplyTo); err == nil {
+			msg.ReplyTo = &sendGridAddress{Email: a.Address, Name: a.Name}
+		}
+	}
+	for _, p := range email.Message.Inline {
+		msg.Attachments = append(msg.Attachments, sendGridAttachment{
+			Content: base64.StdEncoding.EncodeToString(p.Data), Type: p.ContentType,
+			Filename: p.Filename, Disposition: "inline", ContentID: p.ContentID,
+		})
+	}
+	for _, p := range email.Message.Attachments {
+		msg.Attachments = append(msg.Attachments, sendGridAttachment{
+			Content: base64.StdEncoding.EncodeToString(p.Data), Type: p.ContentType,
+			Filename: p.Filename, Disposition: "attachment",
+		})
+	}
+	if len(email.Headers) > 0 {
+		msg.Headers = make(map[string]string, len(email.Headers))
+		for _, h := range email.Headers {
+			msg.Headers[h[0]] = h[1]
+		}
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SendGrid message: %w", err)
+	}
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "Bearer " + t.cfg.Email.APIKey,
+	}
+	endpoint := strings.TrimRight(t.cfg.Email.APIBaseURL, "/") + "/v3/mail/send"
+	if _, err := doRequest(ctx, http.MethodPost, endpoint, headers, payload); err != nil {
+		return nil, fmt.Errorf("SendGrid request failed: %w", err)
+	}
+	return nil, nil
+}
+
+// Close implements emailTransport
+func (t *sendGridTransport) Close() error {
+	return nil
+}
+
+// mailgunTransport posts the raw MIME message to the Mailgun messages.mime API.
+type mailgunTransport struct {
+	cfg config.Config
+}
+
+// Deliver implements emailTransport
+func (t *mailgunTransport) Deliver(ctx context.Context, email *outgoingEmail) ([]string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	// Mailgun delivers to the "to" field, so it carries every envelope recipient including bcc
+	if err := w.WriteField("to", strings.Join(email.Envelope, ",")); err != nil {
+		return nil, err
+	}
+	fw, err := w.CreateFormFile("message", "message.mime")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fw.Write(email.Raw); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{
+		"Content-Type":  w.FormDataContentType(),
+		"Authorization": basicAuth("api", t.cfg.Email.APIKey),
+	}
+	endpoint := fmt.Sprintf("%s/v3/%s/messages.mime",
+		strings.TrimRight(t.cfg.Email.APIBaseURL, "/"), url.PathEscape(t.cfg.Email.MailgunDomain))
+	if _, err := doRequest(ctx, http.MethodPost, endpoint, headers, body.Bytes()); err != nil {
+		return nil, fmt.Errorf("Mailgun request failed: %w", err)
+	}
+	return nil, nil
+}
+
+// Close implements emailTransport
+func (t *mailgunTransport) Close() error {
+	return nil
+}
+
+// sesTransport sends the raw MIME message through the SES v2 SendEmail API,
+// signed with AWS Signature Version 4.
+type sesTransport struct {
+	cfg config.Config
+}
+
+type sesSendEmailRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Raw struct {
+			Data string `json:"Data"`
+		} `json:"Raw"`
+	} `json:"Content"`
+}
+
+// Deliver implements emailTransport
+func (t *sesTransport) Deliver(ctx context.Context, email *outgoingEmail) ([]string, error) {
+	var req sesSendEmailRequest
+	req.FromEmailAddress = email.From.Address
+	// With raw content the destination addresses are the envelope recipients
+	req.Destination.ToAddresses = email.Envelope
+	req.Content.Raw.Data = base64.StdEncoding.EncodeToString(email.Raw)
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SES request: %w", err)
+	}
+
+	endpoint := strings.TrimRight(t.cfg.Email.APIBaseURL, "/") + "/v2/email/outbound-emails"
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SES endpoint: %w", err)
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+	if t.cfg.Email.SESSessionToken != "" {
+		headers["X-Amz-Security-Token"] = t.cfg.Email.SESSessionToken
+	}
+	signAWSv4(headers, http.MethodPost, u, payload, t.cfg.Email.SESRegion, "ses",
+		t.cfg.Email.SESAccessKeyID, t.cfg.Email.SESSecretAccessKey, time.Now().UTC())
+
+	if _, err := doRequest(ctx, http.MethodPost, endpoint, headers, payload); err != nil {
+		return nil, fmt.Errorf("SES request failed: %w", err)
+	}
+	return nil, nil
+}
+
+// Close implements emailTransport
+func (t *sesTransport) Close() error {
+	return nil
+}
+
+// signAWSv4 adds X-Amz-Date, X-Amz-Content-Sha256 and Authorization headers for an AWS
+// Signature Version 4 request. Every header in headers, plus Host, is signed.
+func signAWSv4(headers map[string]string, method string, u *url.URL, payload []byte, region, service, accessKey, secretKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256.Sum256(payload)
+	headers["X-Amz-Date"] = amzDate
+	headers["X-Amz-Content-Sha256"] = hex.EncodeToString(payloadHash[:])
+
+	canonical := map[string]string{"host": u.Host}
+	for k, v := range headers {
+		canonical[strings.ToLower(k)] = strings.TrimSpace(v)
+	}
+	names := make([]string, 0, len(canonical))
+	for k := range canonical {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + canonical[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		method, path, u.Query().Encode(), canonicalHeaders.String(), signedHeaders, headers["X-Amz-Content-Sha256"],
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	headers["Authorization"] = fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,141 @@
+package providers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"notification-service/internal/config"
+)
+
+// capturedRequest is what the fake mail API received
+type capturedRequest struct {
+	Method, Path, ContentType, Authorization string
+	Header                                   http.Header
+	Body                                     []byte
+}
+
+func fakeMailAPI(t *testing.T, status int) (*httptest.Server, *capturedRequest) {
+	t.Helper()
+	var got capturedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = capturedRequest{r.Method, r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Authorization"), r.Header.Clone(), body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func testOutgoingEmail() *outgoingEmail {
+	msg := &mimeMessage{Text: "pH 9.1", HTML: "<p>pH 9.1</p>"}
+	return &outgoingEmail{
+		From:     mail.Address{Name: "Alerts", Address: "alerts@example.com"},
+		To:       []string{"Ops <ops@example.com>"},
+		Bcc:      []string{"ops@example.com", "archive@example.com"},
+		Subject:  "[Alert 1234abcd] pH out of range",
+		Envelope: []string{"ops@example.com", "archive@example.com"},
+		Headers:  [][2]string{{"Message-ID", "<1@example.com>"}},
+		Message:  msg,
+		Raw:      []byte("Subject: test\r\n\r\nbody\r\n"),
+	}
+}
+
+func TestSendGridTransport(t *testing.T) {
+	srv, got := fakeMailAPI(t, http.StatusAccepted)
+	var cfg config.Config
+	cfg.Email.APIKey, cfg.Email.APIBaseURL = "SG.key", srv.URL
+
+	if _, err := (&sendGridTransport{cfg: cfg}).Deliver(context.Background(), testOutgoingEmail()); err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/v3/mail/send" || got.Authorization != "Bearer SG.key" {
+		t.Errorf("request %s with %q", got.Path, got.Authorization)
+	}
+	var msg sendGridMessage
+	if err := json.Unmarshal(got.Body, &msg); err != nil {
+		t.Fatal(err)
+	}
+	p := msg.Personalizations[0]
+	// The bcc duplicate of a to address is dropped, SendGrid rejects repeats
+	if len(p.To) != 1 || p.To[0].Email != "ops@example.com" || len(p.Bcc) != 1 || p.Bcc[0].Email != "archive@example.com" {
+		t.Errorf("unexpected personalization %+v", p)
+	}
+	if msg.Headers["Message-ID"] != "<1@example.com>" || len(msg.Content) != 2 {
+		t.Errorf("unexpected message %+v", msg)
+	}
+}
+
+func TestMailgunTransport(t *testing.T) {
+	srv, got := fakeMailAPI(t, http.StatusOK)
+	var cfg config.Config
+	cfg.Email.APIKey, cfg.Email.APIBaseURL, cfg.Email.MailgunDomain = "key-1", srv.URL, "mg.example.com"
+
+	if _, err := (&mailgunTransport{cfg: cfg}).Deliver(context.Background(), testOutgoingEmail()); err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/v3/mg.example.com/messages.mime" {
+		t.Errorf("path = %s", got.Path)
+	}
+	if want := "Basic " + base64.StdEncoding.EncodeToString([]byte("api:key-1")); got.Authorization != want {
+		t.Errorf("Authorization = %q, want %q", got.Authorization, want)
+	}
+	if !strings.HasPrefix(got.ContentType, "multipart/form-data") ||
+		!strings.Contains(string(got.Body), "ops@example.com,archive@example.com") ||
+		!strings.Contains(string(got.Body), "Subject: test") {
+		t.Errorf("unexpected form body %q", got.Body)
+	}
+}
+
+func TestSESTransport(t *testing.T) {
+	srv, got := fakeMailAPI(t, http.StatusOK)
+	var cfg config.Config
+	cfg.Email.APIBaseURL, cfg.Email.SESRegion = srv.URL, "ap-southeast-1"
+	cfg.Email.SESAccessKeyID, cfg.Email.SESSecretAccessKey = "AKIDEXAMPLE", "secret"
+
+	if _, err := (&sesTransport{cfg: cfg}).Deliver(context.Background(), testOutgoingEmail()); err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/v2/email/outbound-emails" {
+		t.Errorf("path = %s", got.Path)
+	}
+	if !strings.HasPrefix(got.Authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") ||
+		!strings.Contains(got.Authorization, "/ap-southeast-1/ses/aws4_request") ||
+		got.Header.Get("X-Amz-Date") == "" {
+		t.Errorf("unsigned request: %q", got.Authorization)
+	}
+	var req sesSendEmailRequest
+	if err := json.Unmarshal(got.Body, &req); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(req.Content.Raw.Data)
+	if req.FromEmailAddress != "alerts@example.com" || len(req.Destination.ToAddresses) != 2 || string(raw) != "Subject: test\r\n\r\nbody\r\n" {
+		t.Errorf("unexpected SES request %+v", req)
+	}
+}
+
+func TestEmailAPIErrorsArePermanentOn4xx(t *testing.T) {
+	for status, permanent := range map[int]bool{
+		http.StatusBadRequest:          true,
+		http.StatusUnauthorized:        true,
+		http.StatusTooManyRequests:     false,
+		http.StatusInternalServerError: false,
+	} {
+		srv, _ := fakeMailAPI(t, status)
+		var cfg config.Config
+		cfg.Email.APIKey, cfg.Email.APIBaseURL = "SG.key", srv.URL
+		_, err := (&sendGridTransport{cfg: cfg}).Deliver(context.Background(), testOutgoingEmail())
+		if err == nil {
+			t.Fatalf("status %d: expected error", status)
+		}
+		if isPermanentEmailError(err) != permanent {
+			t.Errorf("status %d: permanent = %v, want %v", status, !permanent, permanent)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"notification-service/internal/utils"
+)
+
+// httpClient is shared by providers that deliver over HTTP.
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// maxResponseBody caps how much of a response body is read for error reporting.
+const maxResponseBody = 4096
+
+// StatusError reports a non-2xx HTTP response from a provider endpoint.
+type StatusError struct {
+	StatusCode int
+	Host       string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d from %s: %s", e.StatusCode, e.Host, e.Body)
+}
+
+// doRequest sends an HTTP request and returns the response body, or an error on non-2xx status.
+func doRequest(ctx context.Context, method, rawURL string, headers map[string]string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return respBody, &StatusError{StatusCode: resp.StatusCode, Host: req.URL.Host, Body: string(respBody)}
+	}
+	return respBody, nil
+}
+
+// isPermanentHTTPError reports whether err is a 4xx response that resending cannot fix.
+// Timeouts (408) and rate limiting (429) are worth retrying.
+func isPermanentHTTPError(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode >= 400 && statusErr.StatusCode < 500 &&
+		statusErr.StatusCode != http.StatusRequestTimeout && statusErr.StatusCode != http.StatusTooManyRequests
+}
+
+// retryableHTTPError marks err as permanent for utils.Retry when it is a permanent 4xx response.
+func retryableHTTPError(err error) error {
+	if isPermanentHTTPError(err) {
+		return utils.Permanent(err)
+	}
+	return err
+}
+
+// validateURL checks that rawURL is an absolute http(s) URL.
+func validateURL(rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("url is required")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url %q must use http or https", rawURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("url %q has no host", rawURL)
+	}
+	return nil
+}
+
+// basicAuth returns an HTTP Basic Authorization header value.
+func basicAuth(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"notification-service/internal/config"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+)
+
+// kafkaTopicPattern matches legal Kafka topic names
+var kafkaTopicPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+
+// kafkaSinkConfig holds the destination topic for a kafka contact point.
+type kafkaSinkConfig struct {
+	Topic string `json:"topic"`
+}
+
+// KafkaProvider publishes notifications as JSON to a topic on the service's own
+// Kafka cluster (KAFKA_BROKER), keyed by alert ID.
+type KafkaProvider struct {
+	cfg      config.Config
+	logger   *logging.Logger
+	mu       sync.Mutex
+	producer sarama.SyncProducer // created on first send
+}
+
+// NewKafkaProvider constructs a KafkaProvider
+func NewKafkaProvider(cfg config.Config, logger *logging.Logger) *KafkaProvider {
+	return &KafkaProvider{cfg: cfg, logger: logger}
+}
+
+// Name implements Provider
+func (p *KafkaProvider) Name() string {
+	return "kafka"
+}
+
+// Capabilities implements Provider
+func (p *KafkaProvider) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+// ValidateConfig implements Provider
+func (p *KafkaProvider) ValidateConfig(_ context.Context, cfg map[string]interface{}) error {
+	var kCfg kafkaSinkConfig
+	if err := decodeConfig(cfg, &kCfg); err != nil {
+		return err
+	}
+	if !kafkaTopicPattern.MatchString(kCfg.Topic) || kCfg.Topic == "." || kCfg.Topic == ".." {
+		return fmt.Errorf("invalid kafka topic %q", kCfg.Topic)
+	}
+	// Publishing into the alert topic would feed notifications back into the consumer
+	if kCfg.Topic == p.cfg.Kafka.Topic || kCfg.Topic == "alert_notification" {
+		return fmt.Errorf("kafka contact point cannot publish to the alert topic %q", kCfg.Topic)
+	}
+	return nil
+}
+
+// Send implements Provider
+func (p *KafkaProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	var kCfg kafkaSinkConfig
+	if err := decodeConfig(cp.Configuration, &kCfg); err != nil {
+		return fmt.Errorf("invalid Kafka configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	if kCfg.Topic == "" {
+		return fmt.Errorf("missing topic in Kafka configuration for contact point %s", uuid.UUID(cp.ID))
+	}
+	if kCfg.Topic == p.cfg.Kafka.Topic {
+		return fmt.Errorf("kafka contact point %s cannot publish to the alert topic %q", uuid.UUID(cp.ID), kCfg.Topic)
+	}
+
+	producer, err := p.getProducer()
+	if err != nil {
+		return err
+	}
+
+	r, err := p.Render(ctx, notif, cp)
+	if err != nil {
+		return err
+	}
+	msg := &sarama.ProducerMessage{
+		Topic: kCfg.Topic,
+		Key:   sarama.StringEncoder(uuid.UUID(notif.RequestID).String()),
+		Value: sarama.ByteEncoder(r.Payload),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("content-type"), Value: []byte("application/json")},
+			{Key: []byte("type"), Value: []byte(notif.Type)},
+		},
+	}
+
+	// The sync producer retries internally (Producer.Retry.Max)
+	partition, offset, err := producer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to publish notification to topic %s: %w", kCfg.Topic, err)
+	}
+	p.logger.Infof("Published notification %s to %s[%d]@%d", uuid.UUID(notif.RequestID), kCfg.Topic, partition, offset)
+	return nil
+}
+
+// Render implements Provider. Payload is the value of the Kafka message.
+func (p *KafkaProvider) Render(_ context.Context, notif models.Notification, _ models.ContactPoint) (Rendered, error) {
+	return renderJSON(p.Name(), notif)
+}
+
+// Close shuts down the producer if one was created
+func (p *KafkaProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.producer == nil {
+		return nil
+	}
+	err := p.producer.Close()
+	p.producer = nil
+	if err != nil {
+		return fmt.Errorf("failed to close Kafka producer: %w", err)
+	}
+	return nil
+}
+
+// getProducer returns the SyncProducer for KAFKA_BROKER, creating it on first use.
+func (p *KafkaProvider) getProducer() (sarama.SyncProducer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.producer != nil {
+		return p.producer, nil
+	}
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 3
+	config.Producer.Retry.Backoff = time.Second
+	config.Producer.Timeout = 10 * time.Second
+
+	producer, err := sarama.NewSyncProducer([]string{p.cfg.Kafka.Broker}, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka producer for %s: %w", p.cfg.Kafka.Broker, err)
+	}
+	p.producer = producer
+	p.logger.Infof("Kafka producer connected to %s", p.cfg.Kafka.Broker)
+	return producer, nil
+}
@@ -0,0 +1,131 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"notification-service/internal/config"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+)
+
+// matrixConfig holds the room and credentials for a Matrix contact point.
+// HomeserverURL and AccessToken fall back to the service-level Matrix settings.
+type matrixConfig struct {
+	HomeserverURL string `json:"homeserver_url"`
+	RoomID        string `json:"room_id"`
+	AccessToken   string `json:"access_token"`
+}
+
+// matrixMessage is an m.room.message event content with an HTML body
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+// MatrixProvider posts notifications to a Matrix room through the client-server API.
+type MatrixProvider struct {
+	cfg    config.Config
+	logger *logging.Logger
+}
+
+// NewMatrixProvider constructs a MatrixProvider
+func NewMatrixProvider(cfg config.Config, logger *logging.Logger) *MatrixProvider {
+	return &MatrixProvider{cfg: cfg, logger: logger}
+}
+
+// Name implements Provider
+func (p *MatrixProvider) Name() string {
+	return "matrix"
+}
+
+// Capabilities implements Provider
+func (p *MatrixProvider) Capabilities() Capabilities {
+	return Capabilities{RichText: true}
+}
+
+// ValidateConfig implements Provider
+func (p *MatrixProvider) ValidateConfig(_ context.Context, cfg map[string]interface{}) error {
+	mCfg, err := p.parseConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if err := validateURL(mCfg.HomeserverURL); err != nil {
+		return fmt.Errorf("matrix contact point homeserver_url: %w", err)
+	}
+	if !strings.HasPrefix(mCfg.RoomID, "!") || !strings.Contains(mCfg.RoomID, ":") {
+		return fmt.Errorf("room_id %q must be a Matrix room ID like !abc:example.org", mCfg.RoomID)
+	}
+	if mCfg.AccessToken == "" {
+		return fmt.Errorf("access_token is required in configuration for matrix contact point")
+	}
+	return nil
+}
+
+// Send implements Provider
+func (p *MatrixProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	mCfg, err := p.parseConfig(cp.Configuration)
+	if err != nil {
+		return fmt.Errorf("invalid Matrix configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	if mCfg.RoomID == "" || mCfg.AccessToken == "" {
+		return fmt.Errorf("missing room_id or access_token in Matrix configuration for contact point %s", uuid.UUID(cp.ID))
+	}
+
+	r, err := p.Render(ctx, notif, cp)
+	if err != nil {
+		return err
+	}
+
+	// The transaction ID is fixed for the retries of this Send, so the homeserver does not
+	// post the message twice when a retry follows a lost response. It is new on every Send:
+	// a repeat of a firing alert must not be mistaken for a retry, so an alert redelivered
+	// by Kafka is posted again.
+	txnID := fmt.Sprintf("%s-%s-%d", uuid.UUID(notif.RequestID), notif.Type, time.Now().UnixNano())
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimRight(mCfg.HomeserverURL, "/"), url.PathEscape(mCfg.RoomID), url.PathEscape(txnID))
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "Bearer " + mCfg.AccessToken,
+	}
+	return utils.Retry(ctx, p.logger, 3, time.Second, func() error {
+		if _, err := doRequest(ctx, http.MethodPut, endpoint, headers, r.Payload); err != nil {
+			return retryableHTTPError(fmt.Errorf("failed to send Matrix message to %s: %w", mCfg.RoomID, err))
+		}
+		p.logger.Infof("Matrix message sent to room %s", mCfg.RoomID)
+		return nil
+	})
+}
+
+// Render implements Provider
+func (p *MatrixProvider) Render(_ context.Context, notif models.Notification, _ models.ContactPoint) (Rendered, error) {
+	return renderJSON(p.Name(), matrixMessage{
+		MsgType:       "m.notice",
+		Body:          renderChatMessage(notif, markupPlain),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: renderChatMessage(notif, markupHTML),
+	})
+}
+
+// parseConfig decodes a Matrix configuration and fills in service-level defaults
+func (p *MatrixProvider) parseConfig(cfg map[string]interface{}) (matrixConfig, error) {
+	var mCfg matrixConfig
+	if err := decodeConfig(cfg, &mCfg); err != nil {
+		return matrixConfig{}, err
+	}
+	if mCfg.HomeserverURL == "" {
+		mCfg.HomeserverURL = p.cfg.Matrix.HomeserverURL
+	}
+	if mCfg.AccessToken == "" {
+		mCfg.AccessToken = p.cfg.Matrix.AccessToken
+	}
+	return mCfg, nil
+}
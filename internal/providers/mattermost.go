@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+)
+
+// mattermostTextLimit is the maximum post length accepted by Mattermost
+const mattermostTextLimit = 16383
+
+// mattermostConfig holds the incoming webhook URL and optional overrides for a Mattermost contact point.
+type mattermostConfig struct {
+	WebhookURL string `json:"webhook_url"`
+	Channel    string `json:"channel,omitempty"`
+	Username   string `json:"username,omitempty"`
+	IconURL    string `json:"icon_url,omitempty"`
+}
+
+// mattermostMessage is the incoming webhook payload
+type mattermostMessage struct {
+	Text     string `json:"text"`
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+	IconURL  string `json:"icon_url,omitempty"`
+}
+
+// MattermostProvider posts notifications to a Mattermost incoming webhook.
+type MattermostProvider struct {
+	logger *logging.Logger
+}
+
+// NewMattermostProvider constructs a MattermostProvider
+func NewMattermostProvider(logger *logging.Logger) *MattermostProvider {
+	return &MattermostProvider{logger: logger}
+}
+
+// Name implements Provider
+func (p *MattermostProvider) Name() string {
+	return "mattermost"
+}
+
+// Capabilities implements Provider
+func (p *MattermostProvider) Capabilities() Capabilities {
+	return Capabilities{RichText: true, MaxLength: mattermostTextLimit}
+}
+
+// ValidateConfig implements Provider
+func (p *MattermostProvider) ValidateConfig(_ context.Context, cfg map[string]interface{}) error {
+	var mmCfg mattermostConfig
+	if err := decodeConfig(cfg, &mmCfg); err != nil {
+		return err
+	}
+	if err := validateURL(mmCfg.WebhookURL); err != nil {
+		return fmt.Errorf("mattermost contact point webhook_url: %w", err)
+	}
+	return nil
+}
+
+// Send implements Provider
+func (p *MattermostProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	var mmCfg mattermostConfig
+	if err := decodeConfig(cp.Configuration, &mmCfg); err != nil {
+		return fmt.Errorf("invalid Mattermost configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	if mmCfg.WebhookURL == "" {
+		return fmt.Errorf("missing webhook_url in Mattermost configuration for contact point %s", uuid.UUID(cp.ID))
+	}
+
+	r, err := p.Render(ctx, notif, cp)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	return utils.Retry(ctx, p.logger, 3, time.Second, func() error {
+		if _, err := doRequest(ctx, "POST", mmCfg.WebhookURL, headers, r.Payload); err != nil {
+			return retryableHTTPError(fmt.Errorf("failed to send Mattermost message: %w", err))
+		}
+		p.logger.Infof("Mattermost message sent for contact point %s", uuid.UUID(cp.ID))
+		return nil
+	})
+}
+
+// Render implements Provider
+func (p *MattermostProvider) Render(_ context.Context, notif models.Notification, cp models.ContactPoint) (Rendered, error) {
+	var mmCfg mattermostConfig
+	if err := decodeConfig(cp.Configuration, &mmCfg); err != nil {
+		return Rendered{}, fmt.Errorf("invalid Mattermost configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	return renderJSON(p.Name(), mattermostMessage{
+		Text:     truncate(renderChatTemplate(notif, p.Name(), markupMarkdown, p.logger), mattermostTextLimit),
+		Channel:  mmCfg.Channel,
+		Username: mmCfg.Username,
+		IconURL:  mmCfg.IconURL,
+	})
+}
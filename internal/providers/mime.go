@@ -0,0 +1,175 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"time"
+)
+
+// mimeLineLength is the maximum encoded line length for base64 parts (RFC 2045)
+const mimeLineLength = 76
+
+// mimePart is a binary body part: an inline image referenced by Content-ID or an attachment.
+type mimePart struct {
+	ContentType string
+	Filename    string
+	ContentID   string // set for inline parts, referenced from HTML as cid:<ContentID>
+	Data        []byte
+}
+
+// mimeMessage is an email with a plain text and HTML alternative plus optional
+// inline images and attachments.
+type mimeMessage struct {
+	Headers     [][2]string // ordered header fields, values already encoded
+	Text        string
+	HTML        string
+	Inline      []mimePart
+	Attachments []mimePart
+}
+
+// encodeHeader encodes a header value as an RFC 2047 encoded-word when it is not plain ASCII.
+func encodeHeader(s string) string {
+	return mime.QEncoding.Encode("UTF-8", s)
+}
+
+// Bytes renders the message. The body nests as
+// multipart/mixed(multipart/related(multipart/alternative(text, html), inline...), attachments...),
+// omitting the mixed and related layers when there is nothing to put in them.
+func (m *mimeMessage) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	for _, h := range m.Headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h[0], h[1])
+	}
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	w := multipart.NewWriter(&buf)
+	outer := "multipart/alternative"
+	if len(m.Attachments) > 0 {
+		outer = "multipart/mixed"
+	} else if len(m.Inline) > 0 {
+		outer = "multipart/related"
+	}
+	fmt.Fprintf(&buf, "Content-Type: %s; boundary=%q\r\n\r\n", outer, w.Boundary())
+
+	var err error
+	switch outer {
+	case "multipart/mixed":
+		err = m.writeMixed(w)
+	case "multipart/related":
+		err = m.writeRelated(w)
+	default:
+		err = m.writeAlternative(w)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeMixed writes the message content followed by the attachments.
+func (m *mimeMessage) writeMixed(w *multipart.Writer) error {
+	if len(m.Inline) > 0 {
+		if err := nestMultipart(w, "multipart/related", m.writeRelated); err != nil {
+			return err
+		}
+	} else if err := nestMultipart(w, "multipart/alternative", m.writeAlternative); err != nil {
+		return err
+	}
+	for _, a := range m.Attachments {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Type", mime.FormatMediaType(a.ContentType, map[string]string{"name": a.Filename}))
+		h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+		if err := writeBase64Part(w, h, a.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRelated writes the alternative bodies followed by the inline images they reference.
+func (m *mimeMessage) writeRelated(w *multipart.Writer) error {
+	if err := nestMultipart(w, "multipart/alternative", m.writeAlternative); err != nil {
+		return err
+	}
+	for _, p := range m.Inline {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Type", p.ContentType)
+		h.Set("Content-ID", "<"+p.ContentID+">")
+		h.Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": p.Filename}))
+		if err := writeBase64Part(w, h, p.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAlternative writes the plain text body before the HTML body, so clients prefer HTML.
+func (m *mimeMessage) writeAlternative(w *multipart.Writer) error {
+	if err := writeTextPart(w, "text/plain", m.Text); err != nil {
+		return err
+	}
+	return writeTextPart(w, "text/html", m.HTML)
+}
+
+// nestMultipart writes a multipart child of w whose parts are produced by fill.
+func nestMultipart(w *multipart.Writer, contentType string, fill func(*multipart.Writer) error) error {
+	var body bytes.Buffer
+	child := multipart.NewWriter(&body)
+	if err := fill(child); err != nil {
+		return err
+	}
+	if err := child.Close(); err != nil {
+		return err
+	}
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", mime.FormatMediaType(contentType, map[string]string{"boundary": child.Boundary()}))
+	pw, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = pw.Write(body.Bytes())
+	return err
+}
+
+// writeTextPart writes a UTF-8 text part with quoted-printable encoding.
+func writeTextPart(w *multipart.Writer, contentType, text string) error {
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", contentType+"; charset=UTF-8")
+	h.Set("Content-Transfer-Encoding", "quoted-printable")
+	pw, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(pw)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeBase64Part writes a binary part as base64 wrapped at mimeLineLength.
+func writeBase64Part(w *multipart.Writer, h textproto.MIMEHeader, data []byte) error {
+	h.Set("Content-Transfer-Encoding", "base64")
+	pw, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > mimeLineLength {
+		if _, err := pw.Write([]byte(encoded[:mimeLineLength] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[mimeLineLength:]
+	}
+	_, err = pw.Write([]byte(encoded + "\r\n"))
+	return err
+}
@@ -0,0 +1,155 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+)
+
+// teamsConfig holds the incoming webhook (or Workflows) URL for a Teams contact point.
+type teamsConfig struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+// teamsMessage is the webhook envelope carrying an Adaptive Card
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string                   `json:"$schema"`
+	Type    string                   `json:"type"`
+	Version string                   `json:"version"`
+	Body    []map[string]interface{} `json:"body"`
+	MSTeams map[string]string        `json:"msteams,omitempty"`
+}
+
+// TeamsProvider delivers notifications as Adaptive Cards to a Microsoft Teams webhook.
+type TeamsProvider struct {
+	logger *logging.Logger
+}
+
+// NewTeamsProvider constructs a TeamsProvider
+func NewTeamsProvider(logger *logging.Logger) *TeamsProvider {
+	return &TeamsProvider{logger: logger}
+}
+
+// Name implements Provider
+func (p *TeamsProvider) Name() string {
+	return "msteams"
+}
+
+// Capabilities implements Provider
+func (p *TeamsProvider) Capabilities() Capabilities {
+	return Capabilities{RichText: true}
+}
+
+// ValidateConfig implements Provider
+func (p *TeamsProvider) ValidateConfig(_ context.Context, cfg map[string]interface{}) error {
+	var tCfg teamsConfig
+	if err := decodeConfig(cfg, &tCfg); err != nil {
+		return err
+	}
+	if err := validateURL(tCfg.WebhookURL); err != nil {
+		return fmt.Errorf("msteams contact point webhook_url: %w", err)
+	}
+	return nil
+}
+
+// Send implements Provider
+func (p *TeamsProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	var tCfg teamsConfig
+	if err := decodeConfig(cp.Configuration, &tCfg); err != nil {
+		return fmt.Errorf("invalid Teams configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	if tCfg.WebhookURL == "" {
+		return fmt.Errorf("missing webhook_url in Teams configuration for contact point %s", uuid.UUID(cp.ID))
+	}
+
+	r, err := p.Render(ctx, notif, cp)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	return utils.Retry(ctx, p.logger, 3, time.Second, func() error {
+		if _, err := doRequest(ctx, "POST", tCfg.WebhookURL, headers, r.Payload); err != nil {
+			return retryableHTTPError(fmt.Errorf("failed to send Teams card: %w", err))
+		}
+		p.logger.Infof("Teams card sent for contact point %s", uuid.UUID(cp.ID))
+		return nil
+	})
+}
+
+// Render implements Provider
+func (p *TeamsProvider) Render(_ context.Context, notif models.Notification, _ models.ContactPoint) (Rendered, error) {
+	return renderJSON(p.Name(), buildTeamsMessage(notif))
+}
+
+// teamsStyle maps severity onto the Adaptive Card container styles, which stand in for colours.
+func teamsStyle(notif models.Notification) string {
+	if isResolved(notif) {
+		return "good"
+	}
+	switch levelOf(notif.Severity) {
+	case severityCritical, severityError:
+		return "attention"
+	case severityWarning:
+		return "warning"
+	default:
+		return "accent"
+	}
+}
+
+// buildTeamsMessage renders a notification as an Adaptive Card.
+func buildTeamsMessage(notif models.Notification) teamsMessage {
+	var facts []map[string]string
+	for _, f := range alertFields(notif.Context) {
+		facts = append(facts, map[string]string{"title": f.Name, "value": f.Value})
+	}
+
+	body := []map[string]interface{}{
+		{
+			"type":  "Container",
+			"style": teamsStyle(notif),
+			"bleed": true,
+			"items": []map[string]interface{}{
+				{"type": "TextBlock", "text": alertTitle(notif), "weight": "Bolder", "size": "Large", "wrap": true},
+			},
+		},
+		{"type": "TextBlock", "text": notif.Body, "wrap": true},
+		{"type": "FactSet", "facts": facts},
+		{
+			"type":     "TextBlock",
+			"text":     fmt.Sprintf("Alert %s • %s", uuid.UUID(notif.RequestID), notif.CreatedAt.Format(time.RFC1123)),
+			"isSubtle": true,
+			"size":     "Small",
+			"wrap":     true,
+		},
+	}
+
+	return teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: adaptiveCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+				MSTeams: map[string]string{"width": "Full"},
+			},
+		}},
+	}
+}
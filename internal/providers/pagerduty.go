@@ -0,0 +1,160 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"notification-service/internal/config"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+)
+
+// pagerDutySummaryLimit is the maximum length of an event summary
+const pagerDutySummaryLimit = 1024
+
+// pagerDutyConfig holds the Events v2 integration key for a PagerDuty contact point.
+type pagerDutyConfig struct {
+	RoutingKey string `json:"routing_key"`
+}
+
+// pagerDutyPayload describes the incident for trigger events
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp,omitempty"`
+	Component     string                 `json:"component,omitempty"`
+	Class         string                 `json:"class,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// pagerDutyEvent is an Events API v2 request body
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// PagerDutyProvider triggers and resolves PagerDuty incidents through the Events API v2.
+type PagerDutyProvider struct {
+	cfg    config.Config
+	logger *logging.Logger
+}
+
+// NewPagerDutyProvider constructs a PagerDutyProvider
+func NewPagerDutyProvider(cfg config.Config, logger *logging.Logger) *PagerDutyProvider {
+	return &PagerDutyProvider{cfg: cfg, logger: logger}
+}
+
+// Name implements Provider
+func (p *PagerDutyProvider) Name() string {
+	return "pagerduty"
+}
+
+// Capabilities implements Provider
+func (p *PagerDutyProvider) Capabilities() Capabilities {
+	return Capabilities{MaxLength: pagerDutySummaryLimit, Resolve: true}
+}
+
+// ValidateConfig implements Provider
+func (p *PagerDutyProvider) ValidateConfig(_ context.Context, cfg map[string]interface{}) error {
+	var pdCfg pagerDutyConfig
+	if err := decodeConfig(cfg, &pdCfg); err != nil {
+		return err
+	}
+	if strings.TrimSpace(pdCfg.RoutingKey) == "" {
+		return fmt.Errorf("routing_key is required in configuration for pagerduty contact point")
+	}
+	return nil
+}
+
+// Send implements Provider. Firing alerts trigger an incident and resolved alerts
+// close it; both use the alert ID as dedup_key so PagerDuty pairs them up.
+func (p *PagerDutyProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	var pdCfg pagerDutyConfig
+	if err := decodeConfig(cp.Configuration, &pdCfg); err != nil {
+		return fmt.Errorf("invalid PagerDuty configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	if pdCfg.RoutingKey == "" {
+		return fmt.Errorf("missing routing_key in PagerDuty configuration for contact point %s", uuid.UUID(cp.ID))
+	}
+
+	event := buildPagerDutyEvent(notif, pdCfg.RoutingKey)
+	r, err := renderJSON(p.Name(), event)
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimRight(p.cfg.PagerDuty.EventsURL, "/") + "/v2/enqueue"
+	headers := map[string]string{"Content-Type": "application/json"}
+	return utils.Retry(ctx, p.logger, 3, time.Second, func() error {
+		if _, err := doRequest(ctx, "POST", endpoint, headers, r.Payload); err != nil {
+			return retryableHTTPError(fmt.Errorf("failed to send PagerDuty %s event: %w", event.EventAction, err))
+		}
+		p.logger.Infof("PagerDuty %s event sent for dedup_key %s", event.EventAction, event.DedupKey)
+		return nil
+	})
+}
+
+// Render implements Provider
+func (p *PagerDutyProvider) Render(_ context.Context, notif models.Notification, cp models.ContactPoint) (Rendered, error) {
+	var pdCfg pagerDutyConfig
+	if err := decodeConfig(cp.Configuration, &pdCfg); err != nil {
+		return Rendered{}, fmt.Errorf("invalid PagerDuty configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	return renderJSON(p.Name(), buildPagerDutyEvent(notif, pdCfg.RoutingKey))
+}
+
+// buildPagerDutyEvent maps a notification onto an Events v2 trigger or resolve event.
+func buildPagerDutyEvent(notif models.Notification, routingKey string) pagerDutyEvent {
+	event := pagerDutyEvent{
+		RoutingKey:  routingKey,
+		EventAction: "trigger",
+		DedupKey:    uuid.UUID(notif.RequestID).String(),
+	}
+	if isResolved(notif) {
+		event.EventAction = "resolve"
+		return event
+	}
+
+	ac := notif.Context
+	event.Payload = &pagerDutyPayload{
+		Summary:   truncate(pagerDutySummary(notif), pagerDutySummaryLimit),
+		Source:    fmt.Sprintf("station-%d", ac.StationID),
+		Severity:  levelOf(notif.Severity).String(),
+		Component: ac.MetricName,
+		Class:     "threshold",
+		CustomDetails: map[string]interface{}{
+			"body":          notif.Body,
+			"station_id":    ac.StationID,
+			"metric_id":     ac.MetricID,
+			"metric_name":   ac.MetricName,
+			"operator":      ac.Operator,
+			"threshold":     ac.Threshold,
+			"threshold_min": ac.ThresholdMin,
+			"threshold_max": ac.ThresholdMax,
+			"value":         ac.Value,
+		},
+	}
+	if !notif.CreatedAt.IsZero() {
+		event.Payload.Timestamp = notif.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	return event
+}
+
+// pagerDutySummary is the alert subject, or when it is blank the first line of the body,
+// or else the level tag: PagerDuty refuses events without a summary.
+func pagerDutySummary(notif models.Notification) string {
+	if summary := strings.TrimSpace(notif.Subject); summary != "" {
+		return summary
+	}
+	if line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(notif.Body), "\n", 2)[0]); line != "" {
+		return line
+	}
+	return strings.TrimSpace(alertTitle(notif))
+}
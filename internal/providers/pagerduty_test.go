@@ -0,0 +1,23 @@
+package providers
+
+import (
+	"testing"
+
+	"notification-service/internal/models"
+)
+
+func TestPagerDutySummaryIsNeverEmpty(t *testing.T) {
+	for _, tc := range []struct {
+		notif models.Notification
+		want  string
+	}{
+		{models.Notification{Subject: "pH out of range", Body: "details"}, "pH out of range"},
+		{models.Notification{Subject: " ", Body: "\npH at station 7 is 9.10\nStation: 7"}, "pH at station 7 is 9.10"},
+		{models.Notification{Severity: 4}, "[CRITICAL]"},
+	} {
+		event := buildPagerDutyEvent(tc.notif, "key")
+		if event.Payload == nil || event.Payload.Summary != tc.want {
+			t.Errorf("summary of %+v = %+v, want %q", tc.notif, event.Payload, tc.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"notification-service/internal/models"
+)
+
+// ErrUnsupportedChannel is returned when no Provider is registered for a contact point type.
+var ErrUnsupportedChannel = errors.New("unsupported channel")
+
+// ErrContactPointGone is returned by Send when the destination no longer exists
+// (e.g. an expired push subscription) and the contact point should be retired.
+var ErrContactPointGone = errors.New("contact point is gone")
+
+// Capabilities describes what a Provider's channel can do.
+type Capabilities struct {
+	RichText  bool // body may contain formatting (HTML, Markdown, cards)
+	MaxLength int  // maximum message length in characters, 0 if unbounded
+	Resolve   bool // channel can follow up on a previously sent alert
+}
+
+// Provider delivers Notifications through a single contact point type.
+type Provider interface {
+	// Name returns the contact point type handled by the provider (e.g. "email").
+	Name() string
+	// ValidateConfig checks a contact point Configuration before it is stored.
+	ValidateConfig(ctx context.Context, cfg map[string]interface{}) error
+	// Send delivers the notification to the given contact point.
+	Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error
+	// Render returns what Send would deliver to the contact point, without sending it.
+	// Only the configuration that shapes the content is used, the destination may be missing.
+	Render(ctx context.Context, notif models.Notification, cp models.ContactPoint) (Rendered, error)
+	// Capabilities reports the channel features.
+	Capabilities() Capabilities
+}
+
+// Rendered is the content of a notification as a provider delivers it.
+type Rendered struct {
+	Channel  string          `json:"channel"`
+	Subject  string          `json:"subject,omitempty"`  // email subject line
+	Text     string          `json:"text,omitempty"`     // email text part, chat or SMS message
+	HTML     string          `json:"html,omitempty"`     // email HTML part
+	Messages []string        `json:"messages,omitempty"` // the messages a long chat text is split into
+	Format   string          `json:"format,omitempty"`   // markup of Text, e.g. Telegram's parse mode
+	Payload  json.RawMessage `json:"payload,omitempty"`  // JSON body posted to the channel's API
+}
+
+// renderJSON renders the payload of a channel that posts JSON.
+func renderJSON(channel string, v interface{}) (Rendered, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return Rendered{}, fmt.Errorf("failed to marshal %s payload: %w", channel, err)
+	}
+	return Rendered{Channel: channel, Payload: payload}, nil
+}
+
+// MessageRefStore persists a reference to the first message a provider sent for an
+// alert, so follow-ups with the same RequestID can thread onto it.
+type MessageRefStore interface {
+	// GetMessageRef returns the stored reference, or "" if there is none.
+	GetMessageRef(ctx context.Context, requestID, contactPointID [16]byte) (string, error)
+	// SaveMessageRef stores ref unless one already exists for the alert and contact point.
+	SaveMessageRef(ctx context.Context, requestID, contactPointID [16]byte, channel, ref string) error
+}
+
+// AlertActionStore records actions on-call staff take on an alert from a chat channel.
+type AlertActionStore interface {
+	// AcknowledgeAlert marks the alert as acknowledged by the named user.
+	AcknowledgeAlert(ctx context.Context, requestID [16]byte, by string) error
+	// SilenceAlert suppresses further notifications of the alert until the given time.
+	SilenceAlert(ctx context.Context, requestID [16]byte, until time.Time, by string) error
+}
+
+// TelegramLinkStore binds Telegram chats to contact points through one-time /start tokens.
+type TelegramLinkStore interface {
+	// BindTelegramChat consumes the token and records chatID in its contact point.
+	BindTelegramChat(ctx context.Context, token string, chatID int64) ([16]byte, error)
+}
+
+// ContactPointConfigStore lets a provider persist values it learns at send time,
+// such as rotated OAuth tokens, into a contact point's configuration.
+type ContactPointConfigStore interface {
+	// MergeContactPointConfig sets the given keys of the configuration, keeping the others.
+	MergeContactPointConfig(ctx context.Context, contactPointID [16]byte, values map[string]interface{}) error
+}
+
+// Registry maps contact point types to their Provider.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+// NewRegistry constructs an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+// Register adds or replaces the Provider for p.Name()
+func (r *Registry) Register(p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.Name()] = p
+}
+
+// Get returns the Provider for a contact point type, or ErrUnsupportedChannel.
+func (r *Registry) Get(name string) (Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedChannel, name)
+	}
+	return p, nil
+}
+
+// Names lists the registered contact point types in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close releases resources held by providers that implement io.Closer.
+func (r *Registry) Close() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var firstErr error
+	for name, p := range r.providers {
+		if closer, ok := p.(io.Closer); ok {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("failed to close %s provider: %w", name, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// decodeConfig converts a contact point Configuration map into a typed config struct.
+func decodeConfig(cfg map[string]interface{}, out interface{}) error {
+	configBytes, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	if err := json.Unmarshal(configBytes, out); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package providers
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"notification-service/internal/models"
+)
+
+// alertField is a labelled piece of alert context shown by card-style channels.
+type alertField struct {
+	Name  string
+	Value string
+}
+
+// alertTitle returns the headline for a notification, tagged with its severity or resolved state.
+func alertTitle(notif models.Notification) string {
+	if isResolved(notif) {
+		return fmt.Sprintf("[RESOLVED] %s", notif.Subject)
+	}
+	return fmt.Sprintf("[%s] %s", strings.ToUpper(levelOf(notif.Severity).String()), notif.Subject)
+}
+
+// alertFields lists the AlertContext values in display order.
+func alertFields(ac models.AlertContext) []alertField {
+	return []alertField{
+		{Name: "Station ID", Value: fmt.Sprintf("%d", ac.StationID)},
+		{Name: "Metric", Value: fmt.Sprintf("%s (ID %d)", ac.MetricName, ac.MetricID)},
+		{Name: "Operator", Value: ac.Operator},
+		{Name: "Threshold", Value: fmt.Sprintf("%.2f (min %.2f, max %.2f)", ac.Threshold, ac.ThresholdMin, ac.ThresholdMax)},
+		{Name: "Value", Value: fmt.Sprintf("%.2f", ac.Value)},
+	}
+}
+
+// markup describes how a chat channel emphasises text and escapes user content.
+type markup struct {
+	bold    func(string) string
+	escape  func(string) string
+	newline string
+}
+
+var (
+	// markupPlain renders unformatted text
+	markupPlain = markup{
+		bold:    func(s string) string { return s },
+		escape:  func(s string) string { return s },
+		newline: "\n",
+	}
+	// markupTelegramMarkdownV2 renders Telegram's MarkdownV2 parse mode
+	markupTelegramMarkdownV2 = markup{
+		bold:    func(s string) string { return "*" + s + "*" },
+		escape:  escapeTelegramMarkdownV2,
+		newline: "\n",
+	}
+	// markupTelegramHTML renders Telegram's HTML parse mode, which takes literal newlines
+	markupTelegramHTML = markup{
+		bold:    func(s string) string { return "<b>" + s + "</b>" },
+		escape:  html.EscapeString,
+		newline: "\n",
+	}
+	// markupMarkdown renders CommonMark as understood by Mattermost
+	markupMarkdown = markup{
+		bold:    func(s string) string { return "**" + s + "**" },
+		escape:  escapeMarkdown,
+		newline: "\n",
+	}
+	// markupHTML renders the HTML subset accepted by Matrix clients
+	markupHTML = markup{
+		bold:    func(s string) string { return "<b>" + s + "</b>" },
+		escape:  escapeHTML,
+		newline: "<br>",
+	}
+)
+
+// renderChatMessage renders the chat layout shared by Telegram, Matrix and Mattermost:
+// a bold subject, the body, then one bold-labelled line per alert field.
+func renderChatMessage(notif models.Notification, m markup) string {
+	var b strings.Builder
+	b.WriteString(m.bold(m.escape(notif.Subject)))
+	b.WriteString(m.newline)
+	b.WriteString(m.escape(notif.Body))
+	b.WriteString(m.newline)
+	for _, f := range alertFields(notif.Context) {
+		b.WriteString(m.newline)
+		b.WriteString(m.bold(m.escape(f.Name) + ":"))
+		b.WriteString(" ")
+		b.WriteString(m.escape(f.Value))
+	}
+	return b.String()
+}
+
+// escapeMarkdown backslash-escapes CommonMark punctuation
+func escapeMarkdown(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_[]<>~|", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// escapeTelegramMarkdownV2 backslash-escapes every character MarkdownV2 reserves
+func escapeTelegramMarkdownV2(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\_*[]()~`>#+-=|{}.!", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// escapeHTML escapes text for an HTML body, keeping its line breaks
+func escapeHTML(s string) string {
+	return strings.ReplaceAll(html.EscapeString(s), "\n", "<br>")
+}
+
+// truncate shortens s to at most max runes, marking the cut with an ellipsis.
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if max <= 0 || len(r) <= max {
+		return s
+	}
+	if max == 1 {
+		return "…"
+	}
+	return string(r[:max-1]) + "…"
+}
@@ -0,0 +1,87 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+
+	"notification-service/internal/models"
+)
+
+// severityLevel buckets the numeric alert severity for channel formatting.
+type severityLevel int
+
+const (
+	severityInfo severityLevel = iota
+	severityWarning
+	severityError
+	severityCritical
+)
+
+// Colours used by card-style channels, as hex RGB without the leading '#'.
+const (
+	colorInfo     = "439FE0"
+	colorWarning  = "FFA500"
+	colorError    = "E8590C"
+	colorCritical = "D00000"
+	colorResolved = "2EB886"
+)
+
+// levelOf maps an alert severity (1 = lowest) to a severityLevel.
+func levelOf(severity int) severityLevel {
+	switch {
+	case severity >= 4:
+		return severityCritical
+	case severity == 3:
+		return severityError
+	case severity == 2:
+		return severityWarning
+	default:
+		return severityInfo
+	}
+}
+
+// String returns the lower-case level name
+func (l severityLevel) String() string {
+	switch l {
+	case severityCritical:
+		return "critical"
+	case severityError:
+		return "error"
+	case severityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// parseSeverityLevel is the inverse of severityLevel.String
+func parseSeverityLevel(name string) (severityLevel, error) {
+	for l := severityInfo; l <= severityCritical; l++ {
+		if strings.EqualFold(name, l.String()) {
+			return l, nil
+		}
+	}
+	return severityInfo, fmt.Errorf("unknown severity level %q, use info, warning, error or critical", name)
+}
+
+// isResolved reports whether the notification closes a previously firing alert.
+func isResolved(notif models.Notification) bool {
+	return notif.Type == "resolved"
+}
+
+// severityColor returns the card colour for a notification, green when resolved.
+func severityColor(notif models.Notification) string {
+	if isResolved(notif) {
+		return colorResolved
+	}
+	switch levelOf(notif.Severity) {
+	case severityCritical:
+		return colorCritical
+	case severityError:
+		return colorError
+	case severityWarning:
+		return colorWarning
+	default:
+		return colorInfo
+	}
+}
@@ -0,0 +1,163 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+)
+
+// Block Kit text limits, in characters
+const (
+	slackHeaderLimit  = 150
+	slackSectionLimit = 3000
+	slackFieldLimit   = 2000
+)
+
+// slackConfig holds the incoming webhook URL for a Slack contact point.
+type slackConfig struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+// slackText is a Block Kit text object
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackBlock is a Block Kit layout block
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackAttachment wraps blocks so that the message gets a coloured side bar
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackMessage is the incoming webhook payload
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// SlackProvider delivers notifications to a Slack incoming webhook.
+type SlackProvider struct {
+	logger *logging.Logger
+}
+
+// NewSlackProvider constructs a SlackProvider
+func NewSlackProvider(logger *logging.Logger) *SlackProvider {
+	return &SlackProvider{logger: logger}
+}
+
+// Name implements Provider
+func (p *SlackProvider) Name() string {
+	return "slack"
+}
+
+// Capabilities implements Provider
+func (p *SlackProvider) Capabilities() Capabilities {
+	return Capabilities{RichText: true}
+}
+
+// ValidateConfig implements Provider
+func (p *SlackProvider) ValidateConfig(_ context.Context, cfg map[string]interface{}) error {
+	var sCfg slackConfig
+	if err := decodeConfig(cfg, &sCfg); err != nil {
+		return err
+	}
+	if err := validateURL(sCfg.WebhookURL); err != nil {
+		return fmt.Errorf("slack contact point webhook_url: %w", err)
+	}
+	return nil
+}
+
+// Send implements Provider
+func (p *SlackProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	var sCfg slackConfig
+	if err := decodeConfig(cp.Configuration, &sCfg); err != nil {
+		return fmt.Errorf("invalid Slack configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	if sCfg.WebhookURL == "" {
+		return fmt.Errorf("missing webhook_url in Slack configuration for contact point %s", uuid.UUID(cp.ID))
+	}
+
+	r, err := p.Render(ctx, notif, cp)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	return utils.Retry(ctx, p.logger, 3, time.Second, func() error {
+		if _, err := doRequest(ctx, "POST", sCfg.WebhookURL, headers, r.Payload); err != nil {
+			return retryableHTTPError(fmt.Errorf("failed to send Slack message: %w", err))
+		}
+		p.logger.Infof("Slack message sent for contact point %s", uuid.UUID(cp.ID))
+		return nil
+	})
+}
+
+// Render implements Provider
+func (p *SlackProvider) Render(_ context.Context, notif models.Notification, _ models.ContactPoint) (Rendered, error) {
+	return renderJSON(p.Name(), buildSlackMessage(notif))
+}
+
+// buildSlackMessage renders a notification as Block Kit sections inside a coloured attachment.
+func buildSlackMessage(notif models.Notification) slackMessage {
+	title := ":rotating_light: " + alertTitle(notif)
+	if isResolved(notif) {
+		title = ":white_check_mark: " + alertTitle(notif)
+	}
+
+	var fields []slackText
+	for _, f := range alertFields(notif.Context) {
+		fields = append(fields, slackText{Type: "mrkdwn", Text: slackMrkdwn(fmt.Sprintf("*%s:*\n", f.Name), f.Value, slackFieldLimit)})
+	}
+
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{Type: "plain_text", Text: truncate(title, slackHeaderLimit)}},
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: slackMrkdwn("", notif.Body, slackSectionLimit)}},
+		{Type: "section", Fields: fields},
+		{Type: "context", Elements: []slackText{
+			{Type: "mrkdwn", Text: fmt.Sprintf("Alert %s • %s", uuid.UUID(notif.RequestID), notif.CreatedAt.Format(time.RFC1123))},
+		}},
+	}
+
+	return slackMessage{
+		Text:        title,
+		Attachments: []slackAttachment{{Color: "#" + severityColor(notif), Blocks: blocks}},
+	}
+}
+
+// slackEscape escapes the control characters of Slack mrkdwn
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// slackMrkdwn escapes s after prefix and shortens it so that the whole text fits in max
+// characters. s is cut before escaping so that an entity is never cut in half.
+func slackMrkdwn(prefix, s string, max int) string {
+	n := max - utf8.RuneCountInString(prefix)
+	for {
+		text := prefix + slackEscape(truncate(s, n))
+		over := utf8.RuneCountInString(text) - max
+		if over <= 0 || n <= 1 {
+			return text
+		}
+		n -= over
+		if n < 1 {
+			n = 1
+		}
+	}
+}
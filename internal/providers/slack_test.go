@@ -0,0 +1,26 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"notification-service/internal/models"
+)
+
+func TestSlackSectionTextFitsLimit(t *testing.T) {
+	notif := models.Notification{Subject: "pH out of range", Body: strings.Repeat("a<b ", 1000)}
+	msg := buildSlackMessage(notif)
+	text := msg.Attachments[0].Blocks[1].Text.Text
+	if n := utf8.RuneCountInString(text); n > slackSectionLimit {
+		t.Errorf("section text is %d characters", n)
+	}
+	if !strings.HasSuffix(text, "…") || strings.Contains(text, "<") {
+		t.Errorf("section text not truncated after escaping: ...%s", text[len(text)-20:])
+	}
+
+	short := buildSlackMessage(models.Notification{Body: "x > y"})
+	if got := short.Attachments[0].Blocks[1].Text.Text; got != "x &gt; y" {
+		t.Errorf("short body rendered as %q", got)
+	}
+}
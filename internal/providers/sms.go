@@ -0,0 +1,210 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"notification-service/internal/config"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+)
+
+// Segment sizes for GSM-7 and UCS-2 encoded SMS. Concatenated messages lose
+// a few characters per segment to the UDH header.
+const (
+	smsGSMSingle  = 160
+	smsGSMMulti   = 153
+	smsUCS2Single = 70
+	smsUCS2Multi  = 67
+)
+
+// e164Pattern matches an E.164 phone number such as +84901234567
+var e164Pattern = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+
+// gsm7Basic is the GSM 03.38 basic character set; gsm7Extended characters take two septets.
+const (
+	gsm7Basic    = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	gsm7Extended = "^{}\\[~]|€\f"
+)
+
+// smsConfig holds the destination phone number for an SMS contact point.
+type smsConfig struct {
+	PhoneNumber string `json:"phone_number"`
+}
+
+// SMSProvider delivers notifications through a Twilio-compatible HTTP SMS gateway.
+type SMSProvider struct {
+	cfg    config.Config
+	logger *logging.Logger
+}
+
+// NewSMSProvider constructs an SMSProvider
+func NewSMSProvider(cfg config.Config, logger *logging.Logger) *SMSProvider {
+	return &SMSProvider{cfg: cfg, logger: logger}
+}
+
+// Name implements Provider
+func (p *SMSProvider) Name() string {
+	return "sms"
+}
+
+// Capabilities implements Provider
+func (p *SMSProvider) Capabilities() Capabilities {
+	return Capabilities{MaxLength: smsGSMSingle}
+}
+
+// ValidateConfig implements Provider
+func (p *SMSProvider) ValidateConfig(_ context.Context, cfg map[string]interface{}) error {
+	var sCfg smsConfig
+	if err := decodeConfig(cfg, &sCfg); err != nil {
+		return err
+	}
+	if !e164Pattern.MatchString(sCfg.PhoneNumber) {
+		return fmt.Errorf("phone_number %q must be in E.164 format (e.g. +84901234567)", sCfg.PhoneNumber)
+	}
+	return nil
+}
+
+// Send implements Provider
+func (p *SMSProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	var sCfg smsConfig
+	if err := decodeConfig(cp.Configuration, &sCfg); err != nil {
+		return fmt.Errorf("invalid SMS configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	if !e164Pattern.MatchString(sCfg.PhoneNumber) {
+		return fmt.Errorf("invalid phone_number in SMS configuration for contact point %s", uuid.UUID(cp.ID))
+	}
+
+	gw := p.cfg.SMS
+	if gw.AccountSID == "" || gw.AuthToken == "" || gw.FromNumber == "" {
+		return fmt.Errorf("incomplete SMS gateway settings: account SID/auth token/from number required")
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", strings.TrimRight(gw.GatewayURL, "/"), url.PathEscape(gw.AccountSID))
+
+	r, err := p.Render(ctx, notif, cp)
+	if err != nil {
+		return err
+	}
+	form := url.Values{}
+	form.Set("To", sCfg.PhoneNumber)
+	form.Set("From", gw.FromNumber)
+	form.Set("Body", r.Text)
+
+	headers := map[string]string{
+		"Content-Type":  "application/x-www-form-urlencoded",
+		"Authorization": basicAuth(gw.AccountSID, gw.AuthToken),
+	}
+	return utils.Retry(ctx, p.logger, 3, time.Second, func() error {
+		if _, err := doRequest(ctx, "POST", endpoint, headers, []byte(form.Encode())); err != nil {
+			return retryableHTTPError(fmt.Errorf("failed to send SMS to %s: %w", sCfg.PhoneNumber, err))
+		}
+		p.logger.Infof("SMS sent to %s (%d chars)", sCfg.PhoneNumber, len([]rune(r.Text)))
+		return nil
+	})
+}
+
+// Render implements Provider
+func (p *SMSProvider) Render(_ context.Context, notif models.Notification, _ models.ContactPoint) (Rendered, error) {
+	return Rendered{Channel: p.Name(), Text: renderSMS(notif, p.cfg.SMS.MaxSegments)}, nil
+}
+
+// renderSMS builds a concise alert text that fits in maxSegments SMS segments.
+// The subject is shortened first so that the station and value line survives.
+func renderSMS(notif models.Notification, maxSegments int) string {
+	ac := notif.Context
+	prefix := strings.ToUpper(levelOf(notif.Severity).String()) + ": "
+	detail := fmt.Sprintf("\nSt %d %s=%.2f (%s %.2f)", ac.StationID, ac.MetricName, ac.Value, ac.Operator, ac.Threshold)
+	if isResolved(notif) {
+		prefix = "RESOLVED: "
+		detail = fmt.Sprintf("\nSt %d %s=%.2f", ac.StationID, ac.MetricName, ac.Value)
+	}
+
+	text := prefix + notif.Subject + detail
+	gsm := isGSM7(text)
+	limit := smsLimit(gsm, maxSegments)
+	if smsLength(text, gsm) <= limit {
+		return text
+	}
+
+	ellipsis := smsEllipsis(gsm)
+	subject := []rune(notif.Subject)
+	for len(subject) > 0 {
+		subject = subject[:len(subject)-1]
+		candidate := prefix + strings.TrimRight(string(subject), " ") + ellipsis + detail
+		if smsLength(candidate, gsm) <= limit {
+			return candidate
+		}
+	}
+	return fitSMS(text, maxSegments)
+}
+
+// fitSMS truncates text so that it occupies at most maxSegments segments in its encoding.
+func fitSMS(text string, maxSegments int) string {
+	gsm := isGSM7(text)
+	limit := smsLimit(gsm, maxSegments)
+	if smsLength(text, gsm) <= limit {
+		return text
+	}
+
+	ellipsis := smsEllipsis(gsm)
+	r := []rune(text)
+	for len(r) > 0 && smsLength(string(r)+ellipsis, gsm) > limit {
+		r = r[:len(r)-1]
+	}
+	return strings.TrimRight(string(r), " \n") + ellipsis
+}
+
+// smsLimit returns the character budget for maxSegments segments in the given encoding.
+func smsLimit(gsm bool, maxSegments int) int {
+	if maxSegments < 1 {
+		maxSegments = 1
+	}
+	single, multi := smsUCS2Single, smsUCS2Multi
+	if gsm {
+		single, multi = smsGSMSingle, smsGSMMulti
+	}
+	if maxSegments == 1 {
+		return single
+	}
+	return multi * maxSegments
+}
+
+// smsEllipsis marks truncated text without forcing a GSM-7 message into UCS-2.
+func smsEllipsis(gsm bool) string {
+	if gsm {
+		return "..."
+	}
+	return "…"
+}
+
+// isGSM7 reports whether text can be encoded with the GSM-7 alphabet.
+func isGSM7(text string) bool {
+	for _, c := range text {
+		if !strings.ContainsRune(gsm7Basic, c) && !strings.ContainsRune(gsm7Extended, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// smsLength counts characters as the network does: septets for GSM-7, UTF-16 units for UCS-2.
+func smsLength(text string, gsm bool) int {
+	n := 0
+	for _, c := range text {
+		switch {
+		case gsm && strings.ContainsRune(gsm7Extended, c):
+			n += 2
+		case !gsm && c > 0xFFFF:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,278 @@
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"notification-service/internal/config"
+)
+
+// SMTP transport security modes
+const (
+	SMTPSecurityAuto     = "auto"     // STARTTLS when the server offers it (net/smtp default)
+	SMTPSecurityStartTLS = "starttls" // STARTTLS required
+	SMTPSecurityTLS      = "tls"      // implicit TLS, usually port 465
+	SMTPSecurityNone     = "none"     // plain connection, for local relays
+)
+
+// SMTP authentication mechanisms
+const (
+	SMTPAuthPlain   = "plain"
+	SMTPAuthLogin   = "login"
+	SMTPAuthCRAMMD5 = "cram-md5"
+	SMTPAuthXOAuth2 = "xoauth2"
+	SMTPAuthNone    = "none"
+)
+
+// caPools caches CA bundles by file path
+var (
+	caPoolsMu sync.Mutex
+	caPools   = map[string]*x509.CertPool{}
+)
+
+// smtpConn is an SMTP client together with its underlying network connection.
+type smtpConn struct {
+	client *smtp.Client
+	conn   net.Conn
+}
+
+// Close ends the SMTP session, dropping the connection if QUIT fails.
+func (c *smtpConn) Close() error {
+	if err := c.client.Quit(); err != nil {
+		return c.client.Close()
+	}
+	return nil
+}
+
+// validateSMTPSettings checks the server, security mode and credentials in cfg.Email.
+func validateSMTPSettings(cfg config.Config) error {
+	smtpCfg := cfg.Email
+	if smtpCfg.SMTPServer == "" {
+		return fmt.Errorf("incomplete SMTP settings: server required")
+	}
+	switch smtpCfg.Security {
+	case SMTPSecurityAuto, SMTPSecurityStartTLS, SMTPSecurityTLS, SMTPSecurityNone:
+	default:
+		return fmt.Errorf("unknown SMTP security mode %q", smtpCfg.Security)
+	}
+	switch smtpCfg.AuthMechanism {
+	case SMTPAuthNone:
+	case SMTPAuthPlain, SMTPAuthLogin, SMTPAuthCRAMMD5:
+		if smtpCfg.Username == "" || smtpCfg.Password == "" {
+			return fmt.Errorf("incomplete SMTP settings: username/password required for %s auth", smtpCfg.AuthMechanism)
+		}
+	case SMTPAuthXOAuth2:
+		if smtpCfg.Username == "" || smtpCfg.OAuth2Token == "" {
+			return fmt.Errorf("incomplete SMTP settings: username/oauth2 token required for xoauth2 auth")
+		}
+	default:
+		return fmt.Errorf("unknown SMTP auth mechanism %q", smtpCfg.AuthMechanism)
+	}
+	return nil
+}
+
+// dialSMTP connects to the configured server, negotiates TLS according to the
+// security mode and authenticates. The connection deadline is set to the
+// configured timeout; callers extend it for long sessions.
+func dialSMTP(ctx context.Context, cfg config.Config) (*smtpConn, error) {
+	smtpCfg := cfg.Email
+	timeout := time.Duration(smtpCfg.ConnectTimeout) * time.Second
+	addr := net.JoinHostPort(smtpCfg.SMTPServer, strconv.Itoa(smtpCfg.SMTPPort))
+
+	tlsConfig, err := smtpTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	if smtpCfg.Security == SMTPSecurityTLS {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake with %s failed: %w", addr, err)
+		}
+		conn = tlsConn
+	}
+
+	client, err := smtp.NewClient(conn, smtpCfg.SMTPServer)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SMTP greeting from %s failed: %w", addr, err)
+	}
+	sc := &smtpConn{client: client, conn: conn}
+
+	if smtpCfg.Security == SMTPSecurityAuto || smtpCfg.Security == SMTPSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				client.Close()
+				return nil, fmt.Errorf("STARTTLS with %s failed: %w", addr, err)
+			}
+		} else if smtpCfg.Security == SMTPSecurityStartTLS {
+			client.Close()
+			return nil, fmt.Errorf("server %s does not offer STARTTLS", addr)
+		}
+	}
+
+	if auth := smtpAuth(cfg); auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("server %s does not support AUTH", addr)
+		}
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP %s authentication failed: %w", smtpCfg.AuthMechanism, err)
+		}
+	}
+	return sc, nil
+}
+
+// send delivers one message over an established session. Recipients the server
+// refuses are returned; the send fails only when none are accepted.
+func (c *smtpConn) send(from string, to []string, msg []byte, timeout time.Duration) ([]string, error) {
+	_ = c.conn.SetDeadline(time.Now().Add(timeout))
+	if err := c.client.Mail(from); err != nil {
+		return nil, fmt.Errorf("MAIL FROM rejected: %w", err)
+	}
+	var (
+		rejected []string
+		rcptErr  error
+	)
+	for _, rcpt := range to {
+		if err := c.client.Rcpt(rcpt); err != nil {
+			if !isSMTPReply(err) {
+				return nil, err
+			}
+			rejected, rcptErr = append(rejected, rcpt), err
+		}
+	}
+	if len(rejected) == len(to) {
+		return rejected, fmt.Errorf("all recipients rejected %v: %w", rejected, rcptErr)
+	}
+	w, err := c.client.Data()
+	if err != nil {
+		return rejected, fmt.Errorf("DATA rejected: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return rejected, fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return rejected, fmt.Errorf("message rejected: %w", err)
+	}
+	return rejected, nil
+}
+
+// smtpTLSConfig builds the TLS client config, trusting the custom CA bundle when configured.
+func smtpTLSConfig(cfg config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{ServerName: cfg.Email.SMTPServer, MinVersion: tls.VersionTLS12}
+	if cfg.Email.CAFile == "" {
+		return tlsConfig, nil
+	}
+
+	caPoolsMu.Lock()
+	defer caPoolsMu.Unlock()
+	pool, ok := caPools[cfg.Email.CAFile]
+	if !ok {
+		pem, err := os.ReadFile(cfg.Email.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SMTP CA bundle: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in SMTP CA bundle %s", cfg.Email.CAFile)
+		}
+		caPools[cfg.Email.CAFile] = pool
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// smtpAuth returns the smtp.Auth for the configured mechanism, or nil for none.
+func smtpAuth(cfg config.Config) smtp.Auth {
+	smtpCfg := cfg.Email
+	switch smtpCfg.AuthMechanism {
+	case SMTPAuthPlain:
+		return smtp.PlainAuth("", smtpCfg.Username, smtpCfg.Password, smtpCfg.SMTPServer)
+	case SMTPAuthLogin:
+		return &loginAuth{username: smtpCfg.Username, password: smtpCfg.Password, host: smtpCfg.SMTPServer}
+	case SMTPAuthCRAMMD5:
+		return smtp.CRAMMD5Auth(smtpCfg.Username, smtpCfg.Password)
+	case SMTPAuthXOAuth2:
+		return &xoauth2Auth{username: smtpCfg.Username, token: smtpCfg.OAuth2Token, host: smtpCfg.SMTPServer}
+	default:
+		return nil
+	}
+}
+
+// loginAuth implements the non-standard but widely deployed AUTH LOGIN mechanism.
+type loginAuth struct {
+	username, password, host string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if err := requireEncryption(server, a.host); err != nil {
+		return "", nil, err
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	prompt := strings.ToLower(strings.TrimSpace(string(fromServer)))
+	switch {
+	case strings.HasPrefix(prompt, "username"):
+		return []byte(a.username), nil
+	case strings.HasPrefix(prompt, "password"):
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+// xoauth2Auth implements the XOAUTH2 mechanism used by Google and Microsoft.
+type xoauth2Auth struct {
+	username, token, host string
+}
+
+func (a *xoauth2Auth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if err := requireEncryption(server, a.host); err != nil {
+		return "", nil, err
+	}
+	return "XOAUTH2", []byte("user=" + a.username + "\x01auth=Bearer " + a.token + "\x01\x01"), nil
+}
+
+func (a *xoauth2Auth) Next(_ []byte, more bool) ([]byte, error) {
+	// On failure the server sends a JSON error and expects an empty response
+	if more {
+		return []byte{}, nil
+	}
+	return nil, nil
+}
+
+// requireEncryption refuses to send credentials in clear text, except to localhost.
+func requireEncryption(server *smtp.ServerInfo, host string) error {
+	if server.Name != host {
+		return errors.New("wrong host name")
+	}
+	if !server.TLS && server.Name != "localhost" && server.Name != "127.0.0.1" && server.Name != "::1" {
+		return errors.New("unencrypted connection")
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"notification-service/internal/config"
+	"notification-service/internal/logging"
+)
+
+const (
+	// smtpHealthCheckAfter is how long a connection may sit idle before it is probed with NOOP on reuse
+	smtpHealthCheckAfter = 5 * time.Second
+	// smtpStatsInterval is how often pool throughput is logged
+	smtpStatsInterval = time.Minute
+)
+
+// pooledSMTPConn is an authenticated session kept open between messages.
+type pooledSMTPConn struct {
+	*smtpConn
+	pipelining bool
+	lastUsed   time.Time
+}
+
+// smtpPool keeps up to size authenticated SMTP sessions open and reuses them across sends.
+type smtpPool struct {
+	cfg         config.Config
+	logger      *logging.Logger
+	idle        chan *pooledSMTPConn
+	slots       chan struct{} // one token per open connection
+	idleTimeout time.Duration
+	stop        chan struct{}
+	closeOnce   sync.Once
+
+	sent, failed, dials atomic.Int64
+}
+
+// newSMTPPool creates a pool and starts its throughput logger.
+func newSMTPPool(cfg config.Config, logger *logging.Logger) *smtpPool {
+	p := &smtpPool{
+		cfg:         cfg,
+		logger:      logger,
+		idle:        make(chan *pooledSMTPConn, cfg.Email.PoolSize),
+		slots:       make(chan struct{}, cfg.Email.PoolSize),
+		idleTimeout: time.Duration(cfg.Email.IdleTimeout) * time.Second,
+		stop:        make(chan struct{}),
+	}
+	go p.logStats()
+	return p
+}
+
+// Send delivers msg to the recipients over a pooled session. A failure on a
+// reused session that is not an SMTP reply (the server dropped it while idle)
+// is retried once on a fresh connection.
+func (p *smtpPool) Send(ctx context.Context, from string, to []string, msg []byte) ([]string, error) {
+	c, reused, err := p.get(ctx)
+	if err != nil {
+		p.failed.Add(1)
+		return nil, err
+	}
+	rejected, err := p.deliver(c, from, to, msg)
+	if err != nil && reused && !isSMTPReply(err) {
+		p.logger.Debugf("Pooled SMTP connection failed (%v), reconnecting", err)
+		if c, err = p.dial(ctx); err != nil {
+			p.failed.Add(1)
+			return nil, err
+		}
+		rejected, err = p.deliver(c, from, to, msg)
+	}
+	if err != nil {
+		p.failed.Add(1)
+		return rejected, err
+	}
+	p.sent.Add(1)
+	return rejected, nil
+}
+
+// Close closes idle connections and stops the throughput logger.
+func (p *smtpPool) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+		for {
+			select {
+			case c := <-p.idle:
+				p.discard(c)
+			default:
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// deliver sends one message and returns the connection to the pool, or discards it
+// when the session is no longer usable.
+func (p *smtpPool) deliver(c *pooledSMTPConn, from string, to []string, msg []byte) ([]string, error) {
+	timeout := time.Duration(p.cfg.Email.ConnectTimeout) * time.Second
+	var (
+		rejected []string
+		err      error
+	)
+	if c.pipelining {
+		rejected, err = c.sendPipelined(from, to, msg, timeout)
+	} else {
+		rejected, err = c.send(from, to, msg, timeout)
+	}
+	if err != nil && (!isSMTPReply(err) || c.client.Reset() != nil) {
+		p.discard(c)
+		return rejected, err
+	}
+	p.put(c)
+	return rejected, err
+}
+
+// get returns an idle connection, or dials a new one while under the size limit,
+// otherwise waits for a connection to be returned.
+func (p *smtpPool) get(ctx context.Context) (*pooledSMTPConn, bool, error) {
+	for {
+		select {
+		case c := <-p.idle:
+			if p.usable(c) {
+				return c, true, nil
+			}
+			continue
+		default:
+		}
+
+		select {
+		case c := <-p.idle:
+			if p.usable(c) {
+				return c, true, nil
+			}
+		case p.slots <- struct{}{}:
+			c, err := p.dialSlot(ctx)
+			if err != nil {
+				<-p.slots
+				return nil, false, err
+			}
+			return c, false, nil
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
+
+// dial replaces a discarded connection, waiting for a free slot.
+func (p *smtpPool) dial(ctx context.Context) (*pooledSMTPConn, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	c, err := p.dialSlot(ctx)
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+	return c, nil
+}
+
+// dialSlot opens a connection for a slot the caller already holds.
+func (p *smtpPool) dialSlot(ctx context.Context) (*pooledSMTPConn, error) {
+	sc, err := dialSMTP(ctx, p.cfg)
+	if err != nil {
+		return nil, err
+	}
+	p.dials.Add(1)
+	pipelining, _ := sc.client.Extension("PIPELINING")
+	return &pooledSMTPConn{smtpConn: sc, pipelining: pipelining, lastUsed: time.Now()}, nil
+}
+
+// usable reports whether an idle connection can be reused, discarding it if not.
+func (p *smtpPool) usable(c *pooledSMTPConn) bool {
+	idleFor := time.Since(c.lastUsed)
+	if idleFor > p.idleTimeout {
+		p.discard(c)
+		return false
+	}
+	if idleFor > smtpHealthCheckAfter {
+		_ = c.conn.SetDeadline(time.Now().Add(time.Duration(p.cfg.Email.ConnectTimeout) * time.Second))
+		if err := c.client.Noop(); err != nil {
+			p.discard(c)
+			return false
+		}
+	}
+	return true
+}
+
+// put returns a healthy connection to the idle set.
+func (p *smtpPool) put(c *pooledSMTPConn) {
+	c.lastUsed = time.Now()
+	select {
+	case <-p.stop:
+		p.discard(c)
+		return
+	default:
+	}
+	select {
+	case p.idle <- c:
+	default:
+		p.discard(c)
+	}
+}
+
+// discard closes a connection and frees its slot.
+func (p *smtpPool) discard(c *pooledSMTPConn) {
+	_ = c.Close()
+	<-p.slots
+}
+
+// logStats logs messages sent and failed per interval while there is traffic.
+func (p *smtpPool) logStats() {
+	ticker := time.NewTicker(smtpStatsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			sent, failed, dials := p.sent.Swap(0), p.failed.Swap(0), p.dials.Swap(0)
+			if sent == 0 && failed == 0 {
+				continue
+			}
+			p.logger.Infof("SMTP throughput: %d sent, %d failed in %s (%.2f msg/s), %d new connections, %d open",
+				sent, failed, smtpStatsInterval, float64(sent)/smtpStatsInterval.Seconds(), dials, len(p.slots))
+		}
+	}
+}
+
+// sendPipelined sends MAIL, RCPT and DATA without waiting for each reply (RFC 2920),
+// then reads the replies in order.
+func (c *smtpConn) sendPipelined(from string, to []string, msg []byte, timeout time.Duration) ([]string, error) {
+	_ = c.conn.SetDeadline(time.Now().Add(timeout))
+	text := c.client.Text
+
+	// Bailing out before every reply is read leaves sequence IDs that a later QUIT
+	// would wait on forever, so the connection is dropped instead of ending the session.
+	abort := func(err error) ([]string, error) {
+		_ = c.client.Close()
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(to)+2)
+	id, err := text.Cmd("MAIL FROM:<%s>", from)
+	if err != nil {
+		return abort(err)
+	}
+	ids = append(ids, id)
+	for _, rcpt := range to {
+		if id, err = text.Cmd("RCPT TO:<%s>", rcpt); err != nil {
+			return abort(err)
+		}
+		ids = append(ids, id)
+	}
+	if id, err = text.Cmd("DATA"); err != nil {
+		return abort(err)
+	}
+	ids = append(ids, id)
+
+	var (
+		mailErr, rcptErr, dataErr error
+		rejected                  []string
+	)
+	for i, id := range ids {
+		text.StartResponse(id)
+		switch {
+		case i == 0:
+			_, _, mailErr = text.ReadResponse(25)
+		case i == len(ids)-1:
+			_, _, dataErr = text.ReadResponse(354)
+		default:
+			if _, _, err := text.ReadResponse(25); err != nil {
+				if !isSMTPReply(err) {
+					text.EndResponse(id)
+					return abort(err)
+				}
+				rejected, rcptErr = append(rejected, to[i-1]), err
+			}
+		}
+		text.EndResponse(id)
+	}
+	if (mailErr != nil || len(rejected) == len(to)) && dataErr == nil {
+		// The server entered DATA anyway; an empty message ends it and is refused
+		_ = text.DotWriter().Close()
+		_, _, _ = text.ReadResponse(250)
+	}
+	if mailErr != nil {
+		return nil, fmt.Errorf("MAIL FROM rejected: %w", mailErr)
+	}
+	if len(rejected) == len(to) {
+		return rejected, fmt.Errorf("all recipients rejected %v: %w", rejected, rcptErr)
+	}
+	if dataErr != nil {
+		return rejected, fmt.Errorf("DATA rejected: %w", dataErr)
+	}
+
+	w := text.DotWriter()
+	if _, err := w.Write(msg); err != nil {
+		return rejected, fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return rejected, fmt.Errorf("failed to write message: %w", err)
+	}
+	if _, _, err := text.ReadResponse(250); err != nil {
+		return rejected, fmt.Errorf("message rejected: %w", err)
+	}
+	return rejected, nil
+}
+
+// isPermanentSMTPError reports whether err is a 5xx reply, which resending will not fix.
+func isPermanentSMTPError(err error) bool {
+	var tpErr *textproto.Error
+	return errors.As(err, &tpErr) && tpErr.Code >= 500
+}
+
+// isSMTPReply reports whether err is a negative reply from the server, after which the session is still usable.
+func isSMTPReply(err error) bool {
+	var tpErr *textproto.Error
+	return errors.As(err, &tpErr)
+}
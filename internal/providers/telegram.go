@@ -2,11 +2,18 @@ package providers
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
 	"github.com/go-telegram/bot"
+	tgmodels "github.com/go-telegram/bot/models"
+	"github.com/google/uuid"
 	"golang.org/x/time/rate"
 	"notification-service/internal/config"
 	"notification-service/internal/logging"
@@ -14,91 +21,653 @@ import (
 	"notification-service/internal/utils"
 )
 
-// telegramConfig holds bot token and chat ID for a Telegram contact point.
+const (
+	// telegramMaxRetryAfter is the longest 429 retry_after a send waits out before giving up
+	telegramMaxRetryAfter = time.Minute
+	// telegramChatInterval is Telegram's limit for one private chat (1 msg/s)
+	telegramChatInterval = time.Second
+	// telegramGroupInterval is Telegram's limit for one group (20 msg/min)
+	telegramGroupInterval = time.Minute / 20
+	// telegramMessageLimit is the most characters Telegram accepts in one message
+	telegramMessageLimit = 4096
+	// telegramMaxParts caps how many messages one long notification is split into
+	telegramMaxParts = 3
+)
+
+// telegramConfig holds bot token and chat IDs for a Telegram contact point.
 type telegramConfig struct {
-	BotToken string `json:"bot_token"`
-	ChatID   string  `json:"chat_id"`
+	BotToken        string   `json:"bot_token"`
+	ChatID          string   `json:"chat_id,omitempty"`
+	ChatIDs         []string `json:"chat_ids,omitempty"`
+	MessageThreadID int      `json:"message_thread_id,omitempty"` // forum topic in a supergroup
+	ParseMode       string   `json:"parse_mode,omitempty"`        // MarkdownV2 (default) or HTML
+	// DisableNotification names a severity level; alerts at or below it are delivered silently
+	DisableNotification string `json:"disable_notification,omitempty"`
+	// ReceiveUpdates lets the service take over the updates of bot_token, which the buttons need
+	ReceiveUpdates bool `json:"receive_updates,omitempty"`
+}
+
+// chatIDs returns chat_id followed by chat_ids, parsed and without duplicates.
+func (c telegramConfig) chatIDs() ([]int64, error) {
+	raw := c.ChatIDs
+	if c.ChatID != "" {
+		raw = append([]string{c.ChatID}, raw...)
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("chat_id or chat_ids is required in configuration for telegram contact point")
+	}
+	seen := make(map[int64]bool, len(raw))
+	var ids []int64
+	for _, s := range raw {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat_id %q: %w", s, err)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
 }
 
-// telegramLimiter is the global rate limiter for Telegram messages
-var telegramLimiter *rate.Limiter
+// silent reports whether notif is delivered without a notification sound.
+func (c telegramConfig) silent(notif models.Notification) (bool, error) {
+	if c.DisableNotification == "" {
+		return false, nil
+	}
+	level, err := parseSeverityLevel(c.DisableNotification)
+	if err != nil {
+		return false, fmt.Errorf("invalid disable_notification: %w", err)
+	}
+	return levelOf(notif.Severity) <= level, nil
+}
 
-// initTelegramLimiter initializes the Telegram rate limiter
-func initTelegramLimiter(ratePerSecond int) {
-	telegramLimiter = rate.NewLimiter(rate.Limit(float64(ratePerSecond)), ratePerSecond)
+// telegramFormat pairs a Bot API parse mode with the markup that renders it.
+type telegramFormat struct {
+	parseMode tgmodels.ParseMode
+	markup    markup
 }
 
-// SendTelegram sends a Notification via the go-telegram/bot library
-func SendTelegram(ctx context.Context, notif models.Notification, cp models.ContactPoint, logger *logging.Logger, cfg config.Config) error {
-	// Initialize rate limiter if not set
-	if telegramLimiter == nil {
-		initTelegramLimiter(cfg.RateLimit.TelegramRateLimiter)
+// telegramFormatFor resolves the parse_mode of a contact point.
+func telegramFormatFor(parseMode string) (telegramFormat, error) {
+	switch strings.ToLower(parseMode) {
+	case "", "markdownv2":
+		return telegramFormat{tgmodels.ParseModeMarkdown, markupTelegramMarkdownV2}, nil
+	case "html":
+		return telegramFormat{tgmodels.ParseModeHTML, markupTelegramHTML}, nil
+	default:
+		return telegramFormat{}, fmt.Errorf("unsupported parse_mode %q, use MarkdownV2 or HTML", parseMode)
 	}
+}
 
-	// Check rate limit
-	if err := telegramLimiter.Wait(ctx); err != nil {
-		return fmt.Errorf("telegram rate limit exceeded: %w", err)
+// TelegramProvider delivers notifications through a Telegram bot.
+type TelegramProvider struct {
+	cfg     config.Config
+	refs    MessageRefStore   // chat and message ID of the first message per alert, nil disables edits
+	actions AlertActionStore  // records button presses, nil disables the buttons
+	links   TelegramLinkStore // binds chats through /start tokens sent to the service bot
+	logger  *logging.Logger
+
+	usernameMu sync.Mutex
+	username   string // of the service bot, for t.me links
+
+	// ctx bounds the update listeners started for each bot, cancel stops them on Close
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	botsMu    sync.Mutex
+	bots      map[string]*bot.Bot // token -> client, getMe runs when a token is first used
+	byID      map[int64]*bot.Bot  // bot ID -> client, for routing webhook updates
+	listening map[string]bool     // tokens whose updates the service receives
+
+	limitersMu sync.Mutex
+	limiters   map[string]*rate.Limiter // token -> overall bot limit
+	chats      map[telegramChatKey]*rate.Limiter
+}
+
+// telegramChatKey identifies a chat as seen by one bot
+type telegramChatKey struct {
+	token  string
+	chatID int64
+}
+
+// NewTelegramProvider constructs a TelegramProvider. refs lets a resolution edit the
+// original alert message, actions records the Acknowledge and Silence buttons pressed
+// under it, and links binds the chats that send /start to the service bot.
+func NewTelegramProvider(cfg config.Config, refs MessageRefStore, actions AlertActionStore, links TelegramLinkStore, logger *logging.Logger) *TelegramProvider {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TelegramProvider{
+		cfg:       cfg,
+		refs:      refs,
+		actions:   actions,
+		links:     links,
+		logger:    logger,
+		ctx:       ctx,
+		cancel:    cancel,
+		bots:      make(map[string]*bot.Bot),
+		byID:      make(map[int64]*bot.Bot),
+		listening: make(map[string]bool),
+		limiters:  make(map[string]*rate.Limiter),
+		chats:     make(map[telegramChatKey]*rate.Limiter),
 	}
+}
+
+// Name implements Provider
+func (p *TelegramProvider) Name() string {
+	return "telegram"
+}
 
-	// Parse configuration
+// Capabilities implements Provider
+func (p *TelegramProvider) Capabilities() Capabilities {
+	return Capabilities{RichText: true, MaxLength: 4096}
+}
+
+// ValidateConfig implements Provider. It connects with the bot token and sends
+// a test message to every chat so that a broken token or chat ID is rejected up front.
+func (p *TelegramProvider) ValidateConfig(ctx context.Context, cfg map[string]interface{}) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration cannot be nil for telegram contact point")
+	}
 	var tCfg telegramConfig
-	configBytes, err := json.Marshal(cp.Configuration)
-	if err != nil {
-		return fmt.Errorf("failed to marshal configuration for contact point %s: %w", cp.ID, err)
+	if err := decodeConfig(cfg, &tCfg); err != nil {
+		return err
 	}
-	if err := json.Unmarshal(configBytes, &tCfg); err != nil {
-		return fmt.Errorf("invalid Telegram configuration for contact point %s: %w", cp.ID, err)
+	chatIDs, err := tCfg.chatIDs()
+	if err != nil {
+		return err
 	}
+	tCfg.BotToken = p.botToken(tCfg)
 	if tCfg.BotToken == "" {
-		return fmt.Errorf("missing bot_token in Telegram configuration for contact point %s", cp.ID)
+		return fmt.Errorf("bot_token is required in configuration for telegram contact point when TELEGRAM_BOT_TOKEN is not set")
+	}
+	if _, err := telegramFormatFor(tCfg.ParseMode); err != nil {
+		return err
+	}
+	if tCfg.DisableNotification != "" {
+		if _, err := parseSeverityLevel(tCfg.DisableNotification); err != nil {
+			return fmt.Errorf("invalid disable_notification: %w", err)
+		}
+	}
+
+	b, err := p.bot(tCfg.BotToken)
+	if err != nil {
+		return err
+	}
+	for _, chatID := range chatIDs {
+		p.logger.Infof("Connecting to Telegram bot to validate chat_id %d", chatID)
+		if err := p.wait(ctx, tCfg.BotToken, chatID); err != nil {
+			return err
+		}
+		_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:          chatID,
+			MessageThreadID: tCfg.MessageThreadID,
+			Text:            "Test message from Notification Service",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send test message to Telegram chat_id %d: %w", chatID, err)
+		}
+		p.logger.Infof("Test message sent to Telegram chat_id %d successfully", chatID)
+	}
+	return nil
+}
+
+// Send implements Provider
+func (p *TelegramProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	return p.SendTelegram(ctx, notif, cp)
+}
+
+// Render implements Provider. Text is split into Messages as it would be sent to each chat.
+func (p *TelegramProvider) Render(_ context.Context, notif models.Notification, cp models.ContactPoint) (Rendered, error) {
+	_, msg, err := p.compose(notif, cp)
+	if err != nil {
+		return Rendered{}, err
+	}
+	return Rendered{
+		Channel:  p.Name(),
+		Text:     msg.text,
+		Messages: splitTelegramMessage(msg.text, msg.format.markup),
+		Format:   string(msg.format.parseMode),
+	}, nil
+}
+
+// compose renders the message for a contact point from the parts of its configuration that shape it.
+func (p *TelegramProvider) compose(notif models.Notification, cp models.ContactPoint) (telegramConfig, telegramOutgoing, error) {
+	var tCfg telegramConfig
+	if err := decodeConfig(cp.Configuration, &tCfg); err != nil {
+		return telegramConfig{}, telegramOutgoing{}, fmt.Errorf("invalid Telegram configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	format, err := telegramFormatFor(tCfg.ParseMode)
+	if err != nil {
+		return telegramConfig{}, telegramOutgoing{}, fmt.Errorf("invalid Telegram configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
 	}
-	if tCfg.ChatID == "" {
-		return fmt.Errorf("missing chat_id in Telegram configuration for contact point %s", cp.ID)
+	silent, err := tCfg.silent(notif)
+	if err != nil {
+		return telegramConfig{}, telegramOutgoing{}, fmt.Errorf("invalid Telegram configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
 	}
+	return tCfg, telegramOutgoing{
+		token:     p.botToken(tCfg),
+		threadID:  tCfg.MessageThreadID,
+		text:      renderChatTemplate(notif, p.Name(), format.markup, p.logger),
+		format:    format,
+		silent:    silent,
+		buttons:   p.actionsEnabled() && p.ownsUpdates(tCfg) && !isResolved(notif),
+		requestID: notif.RequestID,
+	}, nil
+}
+
+// SendTelegram sends a Notification to every chat of the contact point via the go-telegram/bot library
+func (p *TelegramProvider) SendTelegram(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	logger := p.logger
 
 	// Compose message
-	text := fmt.Sprintf(
-		"*%s*\n%s\n\n"+
-			"*Station ID:* %d\n"+
-			"*Metric:* %s (ID %d)\n"+
-			"*Operator:* %s\n"+
-			"*Threshold:* %.2f (min %.2f, max %.2f)\n"+
-			"*Value:* %.2f",
-		notif.Subject,
-		notif.Body,
-		notif.Context.StationID,
-		notif.Context.MetricName,
-		notif.Context.MetricID,
-		notif.Context.Operator,
-		notif.Context.Threshold,
-		notif.Context.ThresholdMin,
-		notif.Context.ThresholdMax,
-		notif.Context.Value,
-	)
-	// Log message
-	logger.Infof("Sending Telegram message to chat_id %s: %s", tCfg.ChatID, text)
-	// Retry sending message
-	return utils.Retry(logger, 3, time.Second, func() error {
+	tCfg, msg, err := p.compose(notif, cp)
+	if err != nil {
+		return err
+	}
+	if msg.token == "" {
+		return fmt.Errorf("missing bot_token in Telegram configuration for contact point %s", uuid.UUID(cp.ID))
+	}
+	chatIDs, err := tCfg.chatIDs()
+	if err != nil {
+		return fmt.Errorf("invalid Telegram configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	// Buttons under a contact point's own bot need the updates it opted in to hand over
+	if msg.buttons && msg.token != p.cfg.Telegram.BotToken {
+		if b, err := p.bot(msg.token); err == nil {
+			p.listenTo(b, msg.token)
+		}
+	}
 
-		b, err := bot.New(tCfg.BotToken)
-		if err != nil {
-			return fmt.Errorf("failed to initialize Telegram bot for contact point %s: %w", cp.ID, err)
+	// Follow-ups of an alert refer to its first message in each chat: a resolution
+	// replaces the original text, a repeat is sent as a reply to it
+	refs := p.messageRefs(ctx, notif, cp)
+	var sent []telegramMessageRef
+	var errs []error
+	for _, chatID := range chatIDs {
+		ref, hasRef := refs[chatID]
+		if hasRef && isResolved(notif) {
+			err := p.edit(ctx, msg.token, ref, telegramSingleMessage(msg.text, msg.format.markup), msg.format.parseMode)
+			if err == nil {
+				logger.Infof("Telegram message %d in chat_id %d edited to resolved", ref.messageID, chatID)
+				continue
+			}
+			logger.Warnf("Failed to edit Telegram message %d in chat_id %d, replying instead: %v", ref.messageID, chatID, err)
+		}
+		replyTo := 0
+		if hasRef {
+			replyTo = ref.messageID
 		}
-		// chatID, err := strconv.ParseInt(tCfg.ChatID, 10, 64)
-		chatIDInt64, err := strconv.ParseInt(tCfg.ChatID, 10, 64)
+		firstID, err := p.sendToChat(ctx, chatID, msg, replyTo)
 		if err != nil {
-			return fmt.Errorf("invalid chat_id: %w", err)
+			errs = append(errs, err)
+			continue
+		}
+		if !hasRef {
+			sent = append(sent, telegramMessageRef{chatID: chatID, messageID: firstID})
+		}
+	}
+
+	// Only the first alert message is recorded, its chats are fixed from then on
+	if len(refs) == 0 && len(sent) > 0 && !isResolved(notif) && p.refs != nil {
+		if err := p.refs.SaveMessageRef(ctx, notif.RequestID, cp.ID, p.Name(), formatTelegramMessageRefs(sent)); err != nil {
+			logger.Warnf("Failed to store Telegram message_id for alert %s: %v", uuid.UUID(notif.RequestID), err)
 		}
+	}
+	return errors.Join(errs...)
+}
+
+// telegramOutgoing is a rendered notification ready to be sent to any chat of a contact point.
+type telegramOutgoing struct {
+	token     string
+	threadID  int
+	text      string
+	format    telegramFormat
+	silent    bool
+	buttons   bool
+	requestID [16]byte
+}
+
+// sendToChat sends msg to one chat, split to fit Telegram's length limit, and
+// returns the ID of the first part. replyTo threads it onto an earlier message.
+func (p *TelegramProvider) sendToChat(ctx context.Context, chatID int64, msg telegramOutgoing, replyTo int) (int, error) {
+	parts := splitTelegramMessage(msg.text, msg.format.markup)
+	p.logger.Infof("Sending Telegram message to chat_id %d in %d part(s)", chatID, len(parts))
 
+	firstID := 0
+	for i, part := range parts {
 		params := &bot.SendMessageParams{
-			ChatID:    chatIDInt64,
-			Text:      text,
-			ParseMode: "Markdown",
+			ChatID:              chatID,
+			MessageThreadID:     msg.threadID,
+			Text:                part,
+			ParseMode:           msg.format.parseMode,
+			DisableNotification: msg.silent,
 		}
-		if _, err := b.SendMessage(ctx, params); err != nil {
-			return fmt.Errorf("failed to send Telegram message to chat_id %d: %w", tCfg.ChatID, err)
+		// The first part carries the subject: it gets the buttons and threads onto the original
+		if i == 0 && msg.buttons {
+			params.ReplyMarkup = telegramAlertKeyboard(msg.requestID, true)
+		}
+		if i == 0 && replyTo != 0 {
+			params.ReplyParameters = &tgmodels.ReplyParameters{MessageID: replyTo, AllowSendingWithoutReply: true}
+		}
+		// Retry each part on its own so a retry never repeats a part already delivered
+		err := utils.Retry(ctx, p.logger, 3, time.Second, func() error {
+			b, err := p.bot(msg.token)
+			if err != nil {
+				return err
+			}
+			if err := p.wait(ctx, msg.token, chatID); err != nil {
+				return utils.Permanent(err)
+			}
+			sent, err := b.SendMessage(ctx, params)
+			if err != nil {
+				return telegramRetryError(fmt.Errorf("failed to send Telegram message to chat_id %d: %w", chatID, err))
+			}
+			if i == 0 {
+				firstID = sent.ID
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	p.logger.Infof("Telegram message sent to chat_id %d", chatID)
+	return firstID, nil
+}
+
+// telegramMessageRef locates a sent message. The messages of one alert are stored in
+// message_refs as a comma-separated list of "<chat_id>:<message_id>".
+type telegramMessageRef struct {
+	chatID    int64
+	messageID int
+}
+
+func (r telegramMessageRef) String() string {
+	return fmt.Sprintf("%d:%d", r.chatID, r.messageID)
+}
+
+// parseTelegramMessageRef reverses telegramMessageRef.String.
+func parseTelegramMessageRef(s string) (telegramMessageRef, error) {
+	chat, msg, ok := strings.Cut(s, ":")
+	if !ok {
+		return telegramMessageRef{}, fmt.Errorf("malformed Telegram message ref %q", s)
+	}
+	chatID, err := strconv.ParseInt(chat, 10, 64)
+	if err != nil {
+		return telegramMessageRef{}, fmt.Errorf("malformed Telegram message ref %q: %w", s, err)
+	}
+	messageID, err := strconv.Atoi(msg)
+	if err != nil {
+		return telegramMessageRef{}, fmt.Errorf("malformed Telegram message ref %q: %w", s, err)
+	}
+	return telegramMessageRef{chatID: chatID, messageID: messageID}, nil
+}
+
+// formatTelegramMessageRefs joins the refs of an alert's messages for storage.
+func formatTelegramMessageRefs(refs []telegramMessageRef) string {
+	s := make([]string, len(refs))
+	for i, r := range refs {
+		s[i] = r.String()
+	}
+	return strings.Join(s, ",")
+}
+
+// parseTelegramMessageRefs reverses formatTelegramMessageRefs, keyed by chat ID.
+func parseTelegramMessageRefs(s string) (map[int64]telegramMessageRef, error) {
+	refs := make(map[int64]telegramMessageRef)
+	for _, part := range strings.Split(s, ",") {
+		ref, err := parseTelegramMessageRef(part)
+		if err != nil {
+			return nil, err
+		}
+		refs[ref.chatID] = ref
+	}
+	return refs, nil
+}
+
+// messageRefs returns the first message sent for the alert to each chat of this contact point.
+func (p *TelegramProvider) messageRefs(ctx context.Context, notif models.Notification, cp models.ContactPoint) map[int64]telegramMessageRef {
+	if p.refs == nil {
+		return nil
+	}
+	s, err := p.refs.GetMessageRef(ctx, notif.RequestID, cp.ID)
+	if err != nil {
+		p.logger.Warnf("Failed to load Telegram message_id for alert %s, sending a new message: %v", uuid.UUID(notif.RequestID), err)
+		return nil
+	}
+	if s == "" {
+		return nil
+	}
+	refs, err := parseTelegramMessageRefs(s)
+	if err != nil {
+		p.logger.Warnf("Alert %s: %v", uuid.UUID(notif.RequestID), err)
+		return nil
+	}
+	return refs
+}
+
+// edit replaces the text of a sent message and removes its buttons.
+func (p *TelegramProvider) edit(ctx context.Context, token string, ref telegramMessageRef, text string, parseMode tgmodels.ParseMode) error {
+	return utils.Retry(ctx, p.logger, 3, time.Second, func() error {
+		b, err := p.bot(token)
+		if err != nil {
+			return err
+		}
+		if err := p.wait(ctx, token, ref.chatID); err != nil {
+			return utils.Permanent(err)
+		}
+		_, err = b.EditMessageText(ctx, &bot.EditMessageTextParams{
+			ChatID:      ref.chatID,
+			MessageID:   ref.messageID,
+			Text:        text,
+			ParseMode:   parseMode,
+			ReplyMarkup: &tgmodels.InlineKeyboardMarkup{InlineKeyboard: [][]tgmodels.InlineKeyboardButton{}},
+		})
+		if err != nil {
+			return telegramRetryError(fmt.Errorf("failed to edit Telegram message: %w", err))
 		}
-		logger.Infof("Telegram message sent to chat_id %s: %s", tCfg.ChatID, text)
 		return nil
 	})
 }
+
+// splitTelegramMessage breaks a rendered message into parts within telegramMessageLimit,
+// at line breaks where possible. Beyond telegramMaxParts the rest is dropped and the
+// last part says so. Lengths are counted in UTF-16 code units of the marked-up text,
+// which is never shorter than what Telegram counts after parsing.
+func splitTelegramMessage(text string, m markup) []string {
+	if utf16Len(text) <= telegramMessageLimit {
+		return []string{text}
+	}
+	marker := telegramTruncatedMarker(m)
+	limit := telegramMessageLimit - utf16Len(marker)
+
+	var parts []string
+	var cur strings.Builder
+	curLen := 0
+	flush := func() {
+		if cur.Len() > 0 {
+			parts = append(parts, cur.String())
+			cur.Reset()
+			curLen = 0
+		}
+	}
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if curLen+utf16Len(line) > limit {
+			flush()
+		}
+		// A single line over the limit is cut, without splitting an escape
+		for utf16Len(line) > limit {
+			head := cutTelegramText(line, limit)
+			parts = append(parts, head)
+			line = line[len(head):]
+		}
+		cur.WriteString(line)
+		curLen += utf16Len(line)
+	}
+	flush()
+
+	if len(parts) > telegramMaxParts {
+		parts = parts[:telegramMaxParts]
+		parts[telegramMaxParts-1] = strings.TrimRight(parts[telegramMaxParts-1], "\n") + marker
+	}
+	return parts
+}
+
+// telegramSingleMessage fits a rendered message into one Telegram message, for edits.
+func telegramSingleMessage(text string, m markup) string {
+	parts := splitTelegramMessage(text, m)
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return strings.TrimRight(parts[0], "\n") + telegramTruncatedMarker(m)
+}
+
+// telegramTruncatedMarker ends a message whose remainder was dropped.
+func telegramTruncatedMarker(m markup) string {
+	return m.newline + m.escape("… (message truncated)")
+}
+
+// cutTelegramText returns the longest prefix of s within limit UTF-16 code units that
+// does not end inside a MarkdownV2 backslash escape or an HTML entity.
+func cutTelegramText(s string, limit int) string {
+	n, end := 0, 0
+	for i, r := range s {
+		n += utf16.RuneLen(r)
+		if n > limit {
+			break
+		}
+		end = i + utf8.RuneLen(r)
+	}
+	head := s[:end]
+	if trailing := len(head) - len(strings.TrimRight(head, "\\")); trailing%2 == 1 {
+		head = head[:len(head)-1]
+	}
+	if amp := strings.LastIndexByte(head, '&'); amp > 0 && !strings.Contains(head[amp:], ";") {
+		head = head[:amp]
+	}
+	if head == "" {
+		return s[:end]
+	}
+	return head
+}
+
+// utf16Len is the length of s as Telegram counts it
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// botToken returns the contact point's own bot token, or the service bot's.
+func (p *TelegramProvider) botToken(tCfg telegramConfig) string {
+	if tCfg.BotToken != "" {
+		return tCfg.BotToken
+	}
+	return p.cfg.Telegram.BotToken
+}
+
+// Close stops the update listeners of all bots.
+func (p *TelegramProvider) Close() error {
+	p.cancel()
+	return nil
+}
+
+// bot returns the cached client for token, creating it (and calling getMe) on first use.
+// The service bot also starts listening for updates, see listen.
+func (p *TelegramProvider) bot(token string) (*bot.Bot, error) {
+	p.botsMu.Lock()
+	b, ok := p.bots[token]
+	p.botsMu.Unlock()
+	if ok {
+		return b, nil
+	}
+
+	// getMe runs without the lock, so that a slow or unreachable token does not hold up other bots
+	b, err := bot.New(token,
+		bot.WithCheckInitTimeout(10*time.Second),
+		bot.WithWebhookSecretToken(telegramWebhookSecret(token)),
+		bot.WithCallbackQueryDataHandler(telegramCallbackPrefix, bot.MatchTypePrefix, p.handleCallback),
+		bot.WithDefaultHandler(func(context.Context, *bot.Bot, *tgmodels.Update) {}),
+		bot.WithErrorsHandler(func(err error) {
+			p.logger.Errorf("Telegram bot error: %v", err)
+		}),
+	)
+	if err != nil {
+		err = fmt.Errorf("failed to initialize Telegram bot: %w", err)
+		if errors.Is(err, bot.ErrorUnauthorized) || errors.Is(err, bot.ErrorNotFound) {
+			return nil, utils.Permanent(err)
+		}
+		return nil, err
+	}
+	if token == p.cfg.Telegram.BotToken && p.links != nil {
+		// Also matches /start@bot_name, which is what a group sees
+		b.RegisterHandler(bot.HandlerTypeMessageText, "/start", bot.MatchTypePrefix, p.handleStart)
+	}
+	p.botsMu.Lock()
+	defer p.botsMu.Unlock()
+	if cached, ok := p.bots[token]; ok {
+		// Another send created it meanwhile, this one was never started
+		return cached, nil
+	}
+	p.bots[token] = b
+	p.byID[b.ID()] = b
+	if token == p.cfg.Telegram.BotToken {
+		p.listening[token] = true
+		go p.listen(b)
+	}
+	return b, nil
+}
+
+// wait blocks until both the bot-wide limit and the per-chat limit allow another message.
+// Groups and channels (negative chat IDs) are held to 20 messages a minute, private chats to one a second.
+func (p *TelegramProvider) wait(ctx context.Context, token string, chatID int64) error {
+	p.limitersMu.Lock()
+	botLimiter, ok := p.limiters[token]
+	if !ok {
+		perSecond := p.cfg.RateLimit.TelegramRateLimiter
+		botLimiter = rate.NewLimiter(rate.Limit(perSecond), perSecond)
+		p.limiters[token] = botLimiter
+	}
+	key := telegramChatKey{token: token, chatID: chatID}
+	chatLimiter, ok := p.chats[key]
+	if !ok {
+		interval := telegramChatInterval
+		if chatID < 0 {
+			interval = telegramGroupInterval
+		}
+		chatLimiter = rate.NewLimiter(rate.Every(interval), 1)
+		p.chats[key] = chatLimiter
+	}
+	p.limitersMu.Unlock()
+
+	if err := chatLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("telegram rate limit exceeded for chat %d: %w", chatID, err)
+	}
+	if err := botLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("telegram rate limit exceeded: %w", err)
+	}
+	return nil
+}
+
+// telegramRetryError classifies a Bot API error for utils.Retry: 429 waits out
+// retry_after, and errors resending cannot fix (bad request, bot blocked or removed,
+// revoked token) are permanent.
+func telegramRetryError(err error) error {
+	var tooMany *bot.TooManyRequestsError
+	if errors.As(err, &tooMany) {
+		wait := time.Duration(tooMany.RetryAfter) * time.Second
+		if wait > telegramMaxRetryAfter {
+			return utils.Permanent(fmt.Errorf("%w (retry_after exceeds %s)", err, telegramMaxRetryAfter))
+		}
+		return utils.RetryAfter(err, wait)
+	}
+	var migrate *bot.MigrateError
+	if errors.As(err, &migrate) || errors.Is(err, bot.ErrorBadRequest) || errors.Is(err, bot.ErrorForbidden) ||
+		errors.Is(err, bot.ErrorUnauthorized) || errors.Is(err, bot.ErrorNotFound) {
+		return utils.Permanent(err)
+	}
+	return err
+}
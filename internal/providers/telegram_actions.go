@@ -0,0 +1,251 @@
+package providers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	tgmodels "github.com/go-telegram/bot/models"
+	"github.com/google/uuid"
+	"notification-service/internal/models"
+)
+
+// telegramCallbackPrefix starts the callback data of every alert button: alert:<action>:<request_id>
+const telegramCallbackPrefix = "alert:"
+
+// telegramAlertAction is what pressing one of the inline buttons under an alert does.
+type telegramAlertAction struct {
+	data    string        // callback data between the prefix and the request ID
+	label   string        // button text
+	silence time.Duration // zero for Acknowledge
+}
+
+var (
+	telegramAck        = telegramAlertAction{data: "ack", label: "✅ Acknowledge"}
+	telegramSilence1h  = telegramAlertAction{data: "silence:1h", label: "🔕 Silence 1h", silence: time.Hour}
+	telegramSilence24h = telegramAlertAction{data: "silence:24h", label: "🔕 Silence 24h", silence: 24 * time.Hour}
+
+	telegramAlertActions = []telegramAlertAction{telegramAck, telegramSilence1h, telegramSilence24h}
+)
+
+// telegramAlertKeyboard builds the buttons attached to an alert message. The
+// Acknowledge button is left out once the alert has been acknowledged.
+func telegramAlertKeyboard(requestID [16]byte, withAck bool) *tgmodels.InlineKeyboardMarkup {
+	id := uuid.UUID(requestID).String()
+	var row []tgmodels.InlineKeyboardButton
+	for _, a := range telegramAlertActions {
+		if a == telegramAck && !withAck {
+			continue
+		}
+		row = append(row, tgmodels.InlineKeyboardButton{
+			Text:         a.label,
+			CallbackData: telegramCallbackPrefix + a.data + ":" + id,
+		})
+	}
+	return &tgmodels.InlineKeyboardMarkup{InlineKeyboard: [][]tgmodels.InlineKeyboardButton{row}}
+}
+
+// parseTelegramCallback splits callback data into the action and the alert's request ID.
+func parseTelegramCallback(data string) (telegramAlertAction, uuid.UUID, error) {
+	rest := strings.TrimPrefix(data, telegramCallbackPrefix)
+	i := strings.LastIndex(rest, ":")
+	if i < 0 {
+		return telegramAlertAction{}, uuid.Nil, fmt.Errorf("malformed callback data %q", data)
+	}
+	requestID, err := uuid.Parse(rest[i+1:])
+	if err != nil {
+		return telegramAlertAction{}, uuid.Nil, fmt.Errorf("invalid request_id in callback data %q: %w", data, err)
+	}
+	for _, a := range telegramAlertActions {
+		if a.data == rest[:i] {
+			return a, requestID, nil
+		}
+	}
+	return telegramAlertAction{}, uuid.Nil, fmt.Errorf("unknown action in callback data %q", data)
+}
+
+// actionsEnabled reports whether bots receive updates, without which the buttons would do nothing.
+func (p *TelegramProvider) actionsEnabled() bool {
+	return p.actions != nil && p.cfg.Telegram.UpdatesMode != "off"
+}
+
+// ownsUpdates reports whether the service receives the updates of the bot a contact point
+// sends through: the service bot, or its own bot when receive_updates opts in. Any other
+// bot's updates belong to whatever integration its owner already runs.
+func (p *TelegramProvider) ownsUpdates(tCfg telegramConfig) bool {
+	return tCfg.ReceiveUpdates || p.botToken(tCfg) == p.cfg.Telegram.BotToken
+}
+
+// listenTo starts receiving updates for a contact point's own bot, unless it already does.
+func (p *TelegramProvider) listenTo(b *bot.Bot, token string) {
+	p.botsMu.Lock()
+	defer p.botsMu.Unlock()
+	if p.listening[token] {
+		return
+	}
+	p.listening[token] = true
+	go p.listen(b)
+}
+
+// listen receives updates for a bot until the provider is closed, by long polling
+// or, when TELEGRAM_UPDATES_MODE is webhook, through the /telegram/webhook route.
+func (p *TelegramProvider) listen(b *bot.Bot) {
+	switch p.cfg.Telegram.UpdatesMode {
+	case "off":
+		return
+	case "webhook":
+		url := strings.TrimRight(p.cfg.Telegram.WebhookURL, "/") + "/" + strconv.FormatInt(b.ID(), 10)
+		_, err := b.SetWebhook(p.ctx, &bot.SetWebhookParams{
+			URL:            url,
+			SecretToken:    telegramWebhookSecret(b.Token()),
+			AllowedUpdates: []string{"message", "callback_query"},
+		})
+		if err != nil {
+			p.logger.Errorf("Failed to set Telegram webhook for bot %d: %v", b.ID(), err)
+			return
+		}
+		p.logger.Infof("Telegram bot %d receives updates at %s", b.ID(), url)
+		b.StartWebhook(p.ctx)
+	default:
+		// getUpdates fails while a webhook is set. Only the service bot's webhook is
+		// ours to delete, a contact point's bot keeps whatever webhook its owner set.
+		if b.Token() == p.cfg.Telegram.BotToken {
+			if _, err := b.DeleteWebhook(p.ctx, &bot.DeleteWebhookParams{}); err != nil {
+				p.logger.Errorf("Failed to delete Telegram webhook for bot %d: %v", b.ID(), err)
+			}
+		}
+		p.logger.Infof("Telegram bot %d is polling for updates", b.ID())
+		b.Start(p.ctx)
+	}
+}
+
+// Listen starts receiving updates for the service bot, which binds chats sent
+// /start, and for the bots of existing contact points that set receive_updates,
+// so that buttons under messages sent before a restart keep working.
+func (p *TelegramProvider) Listen(cps []models.ContactPoint) {
+	if p.cfg.Telegram.UpdatesMode == "off" {
+		return
+	}
+	if p.cfg.Telegram.BotToken != "" {
+		if _, err := p.bot(p.cfg.Telegram.BotToken); err != nil {
+			p.logger.Errorf("Telegram service bot: %v", err)
+		}
+	}
+	if !p.actionsEnabled() {
+		return
+	}
+	for _, cp := range cps {
+		var tCfg telegramConfig
+		if err := decodeConfig(cp.Configuration, &tCfg); err != nil || tCfg.BotToken == "" || !tCfg.ReceiveUpdates {
+			continue
+		}
+		b, err := p.bot(tCfg.BotToken)
+		if err != nil {
+			p.logger.Warnf("Telegram contact point %s: %v", uuid.UUID(cp.ID), err)
+			continue
+		}
+		p.listenTo(b, tCfg.BotToken)
+	}
+}
+
+// WebhookHandler returns the handler for updates Telegram posts for the given bot ID.
+func (p *TelegramProvider) WebhookHandler(botID int64) (http.HandlerFunc, bool) {
+	p.botsMu.Lock()
+	defer p.botsMu.Unlock()
+	b, ok := p.byID[botID]
+	if !ok {
+		return nil, false
+	}
+	return b.WebhookHandler(), true
+}
+
+// telegramWebhookSecret derives the X-Telegram-Bot-Api-Secret-Token of a bot's webhook from its token.
+func telegramWebhookSecret(token string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte("telegram-webhook"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// handleCallback records a button press under an alert and edits the message to show who acted.
+func (p *TelegramProvider) handleCallback(ctx context.Context, b *bot.Bot, update *tgmodels.Update) {
+	cq := update.CallbackQuery
+	action, requestID, err := parseTelegramCallback(cq.Data)
+	if err != nil {
+		p.logger.Warnf("Ignoring Telegram callback: %v", err)
+		p.answerCallback(ctx, b, cq.ID, "Unknown action", true)
+		return
+	}
+
+	by := telegramUserName(cq.From)
+	now := time.Now().UTC()
+	var note, answer string
+	if action.silence == 0 {
+		err = p.actions.AcknowledgeAlert(ctx, requestID, by)
+		note = fmt.Sprintf("✅ Acknowledged by %s (%s UTC)", by, now.Format("2006-01-02 15:04"))
+		answer = "Acknowledged"
+	} else {
+		until := now.Add(action.silence)
+		err = p.actions.SilenceAlert(ctx, requestID, until, by)
+		note = fmt.Sprintf("🔕 Silenced until %s UTC by %s", until.Format("2006-01-02 15:04"), by)
+		answer = "Silenced for " + strings.TrimPrefix(action.data, "silence:")
+	}
+	if err != nil {
+		p.logger.Errorf("Telegram %s for alert %s by %s failed: %v", action.data, requestID, by, err)
+		p.answerCallback(ctx, b, cq.ID, "Could not update the alert, please try again", true)
+		return
+	}
+	p.logger.Infof("Alert %s: %s by %s via Telegram", requestID, action.data, by)
+	p.answerCallback(ctx, b, cq.ID, answer, false)
+
+	msg := cq.Message.Message
+	if msg == nil {
+		// Older than 48 hours, Telegram no longer lets the bot edit it
+		return
+	}
+	// After Acknowledge only the Silence buttons remain, after Silence none
+	markup := &tgmodels.InlineKeyboardMarkup{InlineKeyboard: [][]tgmodels.InlineKeyboardButton{}}
+	if action.silence == 0 {
+		markup = telegramAlertKeyboard(requestID, false)
+	}
+	// Appending keeps the offsets of the original entities valid
+	_, err = b.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:      msg.Chat.ID,
+		MessageID:   msg.ID,
+		Text:        msg.Text + "\n\n" + note,
+		Entities:    msg.Entities,
+		ReplyMarkup: markup,
+	})
+	if err != nil {
+		p.logger.Errorf("Failed to edit Telegram message %d in chat %d: %v", msg.ID, msg.Chat.ID, err)
+	}
+}
+
+func (p *TelegramProvider) answerCallback(ctx context.Context, b *bot.Bot, id, text string, alert bool) {
+	_, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: id,
+		Text:            text,
+		ShowAlert:       alert,
+	})
+	if err != nil {
+		p.logger.Errorf("Failed to answer Telegram callback query: %v", err)
+	}
+}
+
+// telegramUserName is how a user is shown in the message and stored in the DB.
+func telegramUserName(u tgmodels.User) string {
+	if u.Username != "" {
+		return "@" + u.Username
+	}
+	name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if name == "" {
+		return strconv.FormatInt(u.ID, 10)
+	}
+	return name
+}
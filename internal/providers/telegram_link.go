@@ -0,0 +1,84 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	tgmodels "github.com/go-telegram/bot/models"
+	"github.com/google/uuid"
+	"notification-service/internal/models"
+)
+
+// TelegramLinkURLs returns the t.me deep links that send /start <token> to the
+// service bot, from a private chat and from a group the user picks.
+func (p *TelegramProvider) TelegramLinkURLs(ctx context.Context, token string) (private, group string, err error) {
+	username, err := p.serviceUsername(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	base := "https://t.me/" + username
+	return base + "?start=" + url.QueryEscape(token), base + "?startgroup=" + url.QueryEscape(token), nil
+}
+
+// serviceUsername returns the service bot's username, asking Telegram once.
+func (p *TelegramProvider) serviceUsername(ctx context.Context) (string, error) {
+	if p.cfg.Telegram.BotToken == "" {
+		return "", fmt.Errorf("TELEGRAM_BOT_TOKEN is not set")
+	}
+	p.usernameMu.Lock()
+	defer p.usernameMu.Unlock()
+	if p.username != "" {
+		return p.username, nil
+	}
+	b, err := p.bot(p.cfg.Telegram.BotToken)
+	if err != nil {
+		return "", err
+	}
+	me, err := b.GetMe(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Telegram service bot: %w", err)
+	}
+	p.username = me.Username
+	return p.username, nil
+}
+
+// handleStart binds the chat a /start <token> message came from to the token's contact point.
+func (p *TelegramProvider) handleStart(ctx context.Context, b *bot.Bot, update *tgmodels.Update) {
+	msg := update.Message
+	_, token, _ := strings.Cut(msg.Text, " ")
+	token = strings.TrimSpace(token)
+	if token == "" {
+		p.reply(ctx, b, msg.Chat.ID, "Open the link from the notification service to connect this chat.")
+		return
+	}
+
+	cpID, err := p.links.BindTelegramChat(ctx, token, msg.Chat.ID)
+	switch {
+	case err == nil:
+		p.logger.Infof("Telegram chat %d bound to contact point %s", msg.Chat.ID, uuid.UUID(cpID))
+		p.reply(ctx, b, msg.Chat.ID, "This chat is now connected and will receive notifications.")
+	case errors.Is(err, models.ErrLinkTokenExpired):
+		p.reply(ctx, b, msg.Chat.ID, "This link has expired. Request a new one from the notification service.")
+	case errors.Is(err, models.ErrLinkTokenUsed):
+		p.reply(ctx, b, msg.Chat.ID, "This link has already been used. Request a new one from the notification service.")
+	case errors.Is(err, models.ErrLinkTokenNotFound):
+		p.reply(ctx, b, msg.Chat.ID, "This link is not valid.")
+	default:
+		p.logger.Errorf("Failed to bind Telegram chat %d: %v", msg.Chat.ID, err)
+		p.reply(ctx, b, msg.Chat.ID, "Something went wrong, please try again.")
+	}
+}
+
+func (p *TelegramProvider) reply(ctx context.Context, b *bot.Bot, chatID int64, text string) {
+	if err := p.wait(ctx, b.Token(), chatID); err != nil {
+		p.logger.Errorf("Failed to reply in Telegram chat %d: %v", chatID, err)
+		return
+	}
+	if _, err := b.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: text}); err != nil {
+		p.logger.Errorf("Failed to reply in Telegram chat %d: %v", chatID, err)
+	}
+}
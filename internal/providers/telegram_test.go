@@ -0,0 +1,161 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"notification-service/internal/config"
+	"notification-service/internal/models"
+)
+
+func TestTelegramMarkdownV2Escaping(t *testing.T) {
+	notif := models.Notification{
+		Subject: "pH_max exceeded (station #7)",
+		Body:    "Value 9.1 > 8.5!",
+		Context: models.AlertContext{MetricName: "ph_level*avg"},
+	}
+	got := renderChatMessage(notif, markupTelegramMarkdownV2)
+	for _, want := range []string{
+		`*pH\_max exceeded \(station \#7\)*`,
+		`Value 9\.1 \> 8\.5\!`,
+		`ph\_level\*avg`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered message does not contain %q:\n%s", want, got)
+		}
+	}
+
+	html := renderChatMessage(notif, markupTelegramHTML)
+	if !strings.Contains(html, "<b>pH_max exceeded (station #7)</b>\n") || !strings.Contains(html, "Value 9.1 &gt; 8.5!") {
+		t.Errorf("unexpected HTML message:\n%s", html)
+	}
+}
+
+func TestSplitTelegramMessage(t *testing.T) {
+	short := "*Subject*\nbody"
+	if parts := splitTelegramMessage(short, markupTelegramMarkdownV2); len(parts) != 1 || parts[0] != short {
+		t.Errorf("short message split into %q", parts)
+	}
+
+	// Lines are kept whole, and an over-long line is not cut inside an escape
+	line := strings.Repeat(`a\.`, 600) + "\n"
+	parts := splitTelegramMessage(strings.Repeat(line, 3), markupTelegramMarkdownV2)
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want 2", len(parts))
+	}
+	for _, p := range parts {
+		if utf16Len(p) > telegramMessageLimit || !strings.HasSuffix(p, "\n") {
+			t.Errorf("part of %d units does not end at a line break", utf16Len(p))
+		}
+	}
+
+	long := strings.Repeat(`x\_`, 5000)
+	parts = splitTelegramMessage(long, markupTelegramMarkdownV2)
+	if len(parts) != telegramMaxParts {
+		t.Fatalf("got %d parts, want %d", len(parts), telegramMaxParts)
+	}
+	for i, p := range parts {
+		if utf16Len(p) > telegramMessageLimit {
+			t.Errorf("part %d is %d units", i, utf16Len(p))
+		}
+		body := strings.TrimSuffix(p, "\n"+escapeTelegramMarkdownV2("… (message truncated)"))
+		if trailing := len(body) - len(strings.TrimRight(body, `\`)); trailing%2 == 1 {
+			t.Errorf("part %d ends inside an escape", i)
+		}
+	}
+	if !strings.HasSuffix(parts[telegramMaxParts-1], `truncated\)`) {
+		t.Error("last part does not mark the truncation")
+	}
+
+	if got := cutTelegramText("ab &amp; cd", 6); got != "ab " {
+		t.Errorf("cut inside an HTML entity: %q", got)
+	}
+}
+
+func TestParseTelegramCallback(t *testing.T) {
+	id := uuid.New()
+	keyboard := telegramAlertKeyboard(id, true)
+	buttons := keyboard.InlineKeyboard[0]
+	if len(buttons) != 3 {
+		t.Fatalf("got %d buttons, want 3", len(buttons))
+	}
+	for i, want := range telegramAlertActions {
+		if len(buttons[i].CallbackData) > 64 {
+			t.Errorf("callback data %q exceeds 64 bytes", buttons[i].CallbackData)
+		}
+		action, requestID, err := parseTelegramCallback(buttons[i].CallbackData)
+		if err != nil || action != want || requestID != id {
+			t.Errorf("parse %q = %v, %s, %v", buttons[i].CallbackData, action, requestID, err)
+		}
+	}
+	if len(telegramAlertKeyboard(id, false).InlineKeyboard[0]) != 2 {
+		t.Error("acknowledged keyboard still offers Acknowledge")
+	}
+	if _, _, err := parseTelegramCallback("alert:snooze:" + id.String()); err == nil {
+		t.Error("unknown action accepted")
+	}
+}
+
+func TestTelegramMessageRef(t *testing.T) {
+	ref := telegramMessageRef{chatID: -1001234567890, messageID: 42}
+	got, err := parseTelegramMessageRef(ref.String())
+	if err != nil || got != ref {
+		t.Errorf("round trip of %q = %+v, %v", ref.String(), got, err)
+	}
+	for _, bad := range []string{"", "42", "x:42", "-100:x"} {
+		if _, err := parseTelegramMessageRef(bad); err == nil {
+			t.Errorf("parseTelegramMessageRef(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestTelegramMessageRefs(t *testing.T) {
+	refs := []telegramMessageRef{{chatID: -100, messageID: 7}, {chatID: 42, messageID: 9}}
+	got, err := parseTelegramMessageRefs(formatTelegramMessageRefs(refs))
+	if err != nil || len(got) != 2 || got[-100] != refs[0] || got[42] != refs[1] {
+		t.Errorf("round trip = %v, %v", got, err)
+	}
+}
+
+func TestTelegramConfigChatsAndSilence(t *testing.T) {
+	cfg := telegramConfig{ChatID: "-100", ChatIDs: []string{"42", "-100"}, DisableNotification: "warning"}
+	ids, err := cfg.chatIDs()
+	if err != nil || len(ids) != 2 || ids[0] != -100 || ids[1] != 42 {
+		t.Errorf("chatIDs = %v, %v", ids, err)
+	}
+	if _, err := (telegramConfig{}).chatIDs(); err == nil {
+		t.Error("config without chats accepted")
+	}
+	if _, err := (telegramConfig{ChatIDs: []string{"@ops"}}).chatIDs(); err == nil {
+		t.Error("non-numeric chat ID accepted")
+	}
+
+	for severity, want := range map[int]bool{1: true, 2: true, 3: false, 4: false} {
+		if got, err := cfg.silent(models.Notification{Severity: severity}); err != nil || got != want {
+			t.Errorf("severity %d: silent = %v, %v, want %v", severity, got, err, want)
+		}
+	}
+	if _, err := (telegramConfig{DisableNotification: "low"}).silent(models.Notification{}); err == nil {
+		t.Error("unknown level accepted")
+	}
+}
+
+func TestTelegramButtonsNeedOwnedUpdates(t *testing.T) {
+	var cfg config.Config
+	cfg.Telegram.BotToken = "1:service"
+	p := NewTelegramProvider(cfg, nil, nil, nil, nil)
+	for _, tc := range []struct {
+		cfg  telegramConfig
+		want bool
+	}{
+		{telegramConfig{}, true},
+		{telegramConfig{BotToken: "1:service"}, true},
+		{telegramConfig{BotToken: "2:customer"}, false},
+		{telegramConfig{BotToken: "2:customer", ReceiveUpdates: true}, true},
+	} {
+		if got := p.ownsUpdates(tc.cfg); got != tc.want {
+			t.Errorf("ownsUpdates(%+v) = %v, want %v", tc.cfg, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package providers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/google/uuid"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+)
+
+const (
+	// maxTemplateSize caps the length of each template source
+	maxTemplateSize = 16 << 10
+	// maxTemplateOutput caps what one template execution may write
+	maxTemplateOutput = 64 << 10
+)
+
+// templateChannels lists the contact point types whose provider renders user-defined templates.
+var templateChannels = map[string]bool{
+	"email":      true,
+	"telegram":   true,
+	"mattermost": true,
+	"zalo":       true,
+}
+
+// errTemplateOutputTooLarge stops an execution that writes more than maxTemplateOutput.
+var errTemplateOutputTooLarge = fmt.Errorf("template output exceeds %d bytes", maxTemplateOutput)
+
+// defaultBodyTemplate is the built-in notification body: the alert message followed by its key context values.
+const defaultBodyTemplate = `{{.Body}}
+Station: {{.Context.StationID}}
+Metric: {{.Context.MetricName}}
+Value: {{printf "%.2f" .Context.Value}}
+Threshold: {{printf "%.2f" .Context.Threshold}}`
+
+// TemplateData is what message templates are executed with.
+type TemplateData struct {
+	Subject   string
+	Body      string
+	Type      string // "alert" or "resolved"
+	Resolved  bool
+	Severity  int
+	Level     string // info, warning, error or critical
+	Title     string // Subject tagged with the level or [RESOLVED], as chat channels show it
+	RequestID string
+	Time      time.Time
+	Context   models.AlertContext
+	Fields    []alertField // Context as labelled values, the only thing templates may range over
+}
+
+// newTemplateData exposes a notification to templates.
+func newTemplateData(notif models.Notification) TemplateData {
+	return TemplateData{
+		Subject:   notif.Subject,
+		Body:      notif.Body,
+		Type:      notif.Type,
+		Resolved:  isResolved(notif),
+		Severity:  notif.Severity,
+		Level:     levelOf(notif.Severity).String(),
+		Title:     alertTitle(notif),
+		RequestID: uuid.UUID(notif.RequestID).String(),
+		Time:      notif.CreatedAt,
+		Context:   notif.Context,
+		Fields:    alertFields(notif.Context),
+	}
+}
+
+// templateFuncs are the only functions available to templates besides the text/template
+// builtins. escape quotes text for the channel's markup.
+func templateFuncs(escape func(string) string) map[string]interface{} {
+	return map[string]interface{}{
+		"escape":  escape,
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"trim":    strings.TrimSpace,
+		"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		// truncate takes the string last so that it can end a pipeline: {{.Body | truncate 200}}
+		"truncate": func(max int, s string) string { return truncate(s, max) },
+	}
+}
+
+// limitedBuffer collects template output up to maxTemplateOutput.
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > maxTemplateOutput {
+		return 0, errTemplateOutputTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+// parseTextTemplate parses a text/template source within the sandbox rules.
+func parseTextTemplate(name, src string, escape func(string) string) (*template.Template, error) {
+	if len(src) > maxTemplateSize {
+		return nil, fmt.Errorf("%s template is longer than %d bytes", name, maxTemplateSize)
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs(escape)).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s template: %w", name, err)
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, fmt.Errorf("%s template: define and block are not allowed", name)
+	}
+	if err := checkTemplateNode(tmpl.Tree.Root, false); err != nil {
+		return nil, fmt.Errorf("%s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// parseHTMLTemplate parses an html/template source within the sandbox rules.
+// escape is the identity, html/template escapes contextually by itself.
+func parseHTMLTemplate(name, src string) (*htmltemplate.Template, error) {
+	if len(src) > maxTemplateSize {
+		return nil, fmt.Errorf("%s template is longer than %d bytes", name, maxTemplateSize)
+	}
+	identity := func(s string) string { return s }
+	tmpl, err := htmltemplate.New(name).Funcs(templateFuncs(identity)).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s template: %w", name, err)
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, fmt.Errorf("%s template: define and block are not allowed", name)
+	}
+	if err := checkTemplateNode(tmpl.Tree.Root, false); err != nil {
+		return nil, fmt.Errorf("%s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// checkTemplateNode rejects the constructs whose cost does not depend on the alert:
+// calls to other templates, which can recurse, range over anything but .Fields,
+// which could loop over an integer such as a station ID, and a range inside another,
+// whose cost grows with the power of the nesting depth. inRange is set inside a range.
+func checkTemplateNode(node parse.Node, inRange bool) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkTemplateNode(child, inRange); err != nil {
+				return err
+			}
+		}
+	case *parse.TemplateNode:
+		return fmt.Errorf("{{template %q}} is not allowed", n.Name)
+	case *parse.IfNode:
+		return checkTemplateBranch(&n.BranchNode, inRange)
+	case *parse.WithNode:
+		return checkTemplateBranch(&n.BranchNode, inRange)
+	case *parse.RangeNode:
+		if inRange {
+			return errors.New("range inside another range is not allowed")
+		}
+		if !rangesOverFields(n.Pipe) {
+			return fmt.Errorf("range is only allowed over .Fields, not %s", n.Pipe)
+		}
+		if err := checkTemplateNode(n.List, true); err != nil {
+			return err
+		}
+		// The else branch runs instead of the loop, not inside it
+		return checkTemplateNode(n.ElseList, inRange)
+	}
+	return nil
+}
+
+func checkTemplateBranch(b *parse.BranchNode, inRange bool) error {
+	if err := checkTemplateNode(b.List, inRange); err != nil {
+		return err
+	}
+	return checkTemplateNode(b.ElseList, inRange)
+}
+
+// rangesOverFields reports whether a range pipeline is .Fields or $.Fields.
+func rangesOverFields(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode:
+		return len(arg.Ident) == 1 && arg.Ident[0] == "Fields"
+	case *parse.VariableNode:
+		return len(arg.Ident) == 2 && arg.Ident[0] == "$" && arg.Ident[1] == "Fields"
+	}
+	return false
+}
+
+// renderTextTemplate executes a text/template source against a notification.
+func renderTextTemplate(name, src string, notif models.Notification, escape func(string) string) (string, error) {
+	tmpl, err := parseTextTemplate(name, src, escape)
+	if err != nil {
+		return "", err
+	}
+	var out limitedBuffer
+	if err := tmpl.Execute(&out, newTemplateData(notif)); err != nil {
+		return "", fmt.Errorf("failed to execute %s template: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+// renderHTMLTemplate executes an html/template source against a notification.
+func renderHTMLTemplate(name, src string, notif models.Notification) (string, error) {
+	tmpl, err := parseHTMLTemplate(name, src)
+	if err != nil {
+		return "", err
+	}
+	var out limitedBuffer
+	if err := tmpl.Execute(&out, newTemplateData(notif)); err != nil {
+		return "", fmt.Errorf("failed to execute %s template: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+// RenderBody renders the body stored for a notification whose Body holds the alert message.
+func RenderBody(notif models.Notification) (string, error) {
+	return renderTextTemplate("body", defaultBodyTemplate, notif, markupPlain.escape)
+}
+
+// ValidateTemplate checks a user-defined template before it is stored: its channel must
+// render templates, and every source must parse within the sandbox rules and execute
+// against a sample alert.
+func ValidateTemplate(t models.Template) error {
+	if !templateChannels[t.Channel] {
+		return fmt.Errorf("channel %q does not support templates, use one of email, mattermost, telegram or zalo", t.Channel)
+	}
+	if strings.TrimSpace(t.Body) == "" {
+		return errors.New("body is required")
+	}
+	if t.Channel != "email" && (t.Subject != "" || t.HTML != "") {
+		return errors.New("subject and html are only used by email templates")
+	}
+	for _, notif := range []models.Notification{sampleNotification("alert"), sampleNotification("resolved")} {
+		if _, err := renderTextTemplate("body", t.Body, notif, markupPlain.escape); err != nil {
+			return err
+		}
+		if t.Subject != "" {
+			if _, err := renderTextTemplate("subject", t.Subject, notif, markupPlain.escape); err != nil {
+				return err
+			}
+		}
+		if t.HTML != "" {
+			if _, err := renderHTMLTemplate("html", t.HTML, notif); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sampleNotification is the alert templates are tried against when they are saved.
+func sampleNotification(typ string) models.Notification {
+	return models.Notification{
+		Type:      typ,
+		Subject:   "pH out of range",
+		Body:      "pH at station 7 is 9.10, above the 8.50 threshold",
+		Severity:  3,
+		RequestID: uuid.New(),
+		CreatedAt: time.Now(),
+		Context: models.AlertContext{
+			StationID:    7,
+			MetricID:     12,
+			MetricName:   "ph",
+			Operator:     ">",
+			Threshold:    8.5,
+			ThresholdMin: 6.5,
+			ThresholdMax: 8.5,
+			Value:        9.1,
+		},
+	}
+}
+
+// messageTemplate returns the user-defined template notif is rendered with on a channel, if any.
+func messageTemplate(notif models.Notification, channel string) *models.Template {
+	if notif.Template == nil || notif.Template.Channel != channel {
+		return nil
+	}
+	return notif.Template
+}
+
+// renderChatTemplate renders a chat message with the contact point's template for the
+// channel, falling back to the built-in chat layout when there is none or it fails.
+func renderChatTemplate(notif models.Notification, channel string, m markup, logger *logging.Logger) string {
+	if t := messageTemplate(notif, channel); t != nil {
+		text, err := renderTextTemplate("body", t.Body, notif, m.escape)
+		if err == nil && strings.TrimSpace(text) == "" {
+			err = errors.New("rendered an empty message")
+		}
+		if err == nil {
+			return text
+		}
+		logger.Warnf("Template %s failed for alert %s, using the built-in format: %v", uuid.UUID(t.ID), uuid.UUID(notif.RequestID), err)
+	}
+	return renderChatMessage(notif, m)
+}
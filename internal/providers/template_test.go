@@ -0,0 +1,157 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"notification-service/internal/config"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+)
+
+func TestRenderBodyMatchesBuiltInFormat(t *testing.T) {
+	notif := sampleNotification("alert")
+	notif.Body = "pH too high"
+	got, err := RenderBody(notif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "pH too high\nStation: 7\nMetric: ph\nValue: 9.10\nThreshold: 8.50"
+	if got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	valid := []models.Template{
+		{Channel: "telegram", Body: `{{if .Resolved}}✅{{else}}🚨{{end}} {{escape .Title}}{{range .Fields}}
+{{escape .Name}}: {{escape .Value}}{{end}}`},
+		{Channel: "email", Subject: "{{.Level | upper}}: {{.Subject}}", Body: "{{.Body | truncate 50}}", HTML: "<h1>{{.Subject}}</h1>"},
+		{Channel: "zalo", Body: `{{range $f := $.Fields}}{{$f.Name}} {{end}}`},
+		{Channel: "mattermost", Body: `{{range .Fields}}{{.Name}}{{else}}none{{end}} {{range .Fields}}{{.Value}}{{end}}`},
+	}
+	for _, tmpl := range valid {
+		if err := ValidateTemplate(tmpl); err != nil {
+			t.Errorf("%s template rejected: %v", tmpl.Channel, err)
+		}
+	}
+
+	invalid := map[string]models.Template{
+		"unsupported channel": {Channel: "slack", Body: "{{.Subject}}"},
+		"empty body":          {Channel: "telegram", Body: " "},
+		"subject on chat":     {Channel: "telegram", Subject: "x", Body: "{{.Subject}}"},
+		"syntax":              {Channel: "telegram", Body: "{{.Subject"},
+		"unknown field":       {Channel: "telegram", Body: "{{.Nope}}"},
+		"unknown function":    {Channel: "telegram", Body: `{{exec "ls"}}`},
+		"define":              {Channel: "telegram", Body: `{{define "x"}}{{end}}{{.Subject}}`},
+		"template call":       {Channel: "telegram", Body: `{{template "body" .}}`},
+		"range over int":      {Channel: "telegram", Body: `{{range .Context.StationID}}x{{end}}`},
+		"nested range":        {Channel: "telegram", Body: `{{range .Fields}}{{if .Name}}{{range $.Fields}}{{end}}{{end}}{{end}}`},
+		"output too large":    {Channel: "telegram", Body: strings.Repeat(`{{printf "%999999d" 1}}`, 2)},
+		"source too large":    {Channel: "telegram", Body: strings.Repeat("x", maxTemplateSize+1)},
+	}
+	for name, tmpl := range invalid {
+		if err := ValidateTemplate(tmpl); err == nil {
+			t.Errorf("%s: template accepted", name)
+		}
+	}
+}
+
+func TestRenderChatTemplate(t *testing.T) {
+	logger, err := logging.New(t.TempDir(), "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notif := sampleNotification("alert")
+	notif.Subject = "pH (station 7)"
+	builtIn := renderChatMessage(notif, markupTelegramMarkdownV2)
+
+	// No template, or one written for another channel
+	if got := renderChatTemplate(notif, "telegram", markupTelegramMarkdownV2, logger); got != builtIn {
+		t.Errorf("without template got %q", got)
+	}
+	notif.Template = &models.Template{Channel: "zalo", Body: "zalo"}
+	if got := renderChatTemplate(notif, "telegram", markupTelegramMarkdownV2, logger); got != builtIn {
+		t.Errorf("with another channel's template got %q", got)
+	}
+
+	notif.Template = &models.Template{Channel: "telegram", Body: "*{{escape .Subject}}* {{.Level}}"}
+	if got := renderChatTemplate(notif, "telegram", markupTelegramMarkdownV2, logger); got != `*pH \(station 7\)* error` {
+		t.Errorf("with template got %q", got)
+	}
+
+	// A template that fails at send time falls back to the built-in layout
+	notif.Template = &models.Template{Channel: "telegram", Body: "{{.Nope}}"}
+	if got := renderChatTemplate(notif, "telegram", markupTelegramMarkdownV2, logger); got != builtIn {
+		t.Errorf("with broken template got %q", got)
+	}
+}
+
+func TestRenderUserEmail(t *testing.T) {
+	notif := sampleNotification("resolved")
+	notif.Subject = "pH <ok>"
+
+	subject, text, html, err := renderUserEmail(&models.Template{
+		Channel: "email",
+		Subject: "{{if .Resolved}}Resolved{{end}}\n{{.Subject}}",
+		Body:    "{{.Subject}} at station {{.Context.StationID}}",
+		HTML:    "<p>{{.Subject}}</p>",
+	}, notif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "Resolved pH <ok>" || text != "pH <ok> at station 7" || html != "<p>pH &lt;ok&gt;</p>" {
+		t.Errorf("got %q, %q, %q", subject, text, html)
+	}
+
+	// Without an HTML template the HTML part repeats the text
+	_, _, html, err = renderUserEmail(&models.Template{Channel: "email", Body: "{{.Subject}}\nline 2"}, notif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html != "<p>pH &lt;ok&gt;<br>line 2</p>" {
+		t.Errorf("html = %q", html)
+	}
+}
+
+func TestRenderDoesNotNeedDestination(t *testing.T) {
+	logger, err := logging.New(t.TempDir(), "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	notif := sampleNotification("alert")
+	notif.Subject = "pH (station 7)"
+	empty := map[string]interface{}{}
+
+	// Webhooks post the notification itself
+	r, err := NewWebhookProvider(logger).Render(ctx, notif, models.ContactPoint{Type: "webhook", Configuration: empty})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(notif)
+	if r.Channel != "webhook" || string(r.Payload) != string(want) {
+		t.Errorf("webhook payload = %s", r.Payload)
+	}
+
+	notif.Template = &models.Template{Channel: "telegram", Body: "*{{escape .Subject}}*"}
+	tg := NewTelegramProvider(config.Config{}, nil, nil, nil, logger)
+	r, err = tg.Render(ctx, notif, models.ContactPoint{Type: "telegram", Configuration: empty})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Text != `*pH \(station 7\)*` || r.Format != "MarkdownV2" || len(r.Messages) != 1 {
+		t.Errorf("telegram preview = %+v", r)
+	}
+
+	notif.Template = &models.Template{Channel: "email", Subject: "{{.Level | upper}}", Body: "{{.Subject}}", HTML: "<b>{{.Subject}}</b>"}
+	r, err = NewEmailProvider(config.Config{}, nil, logger).Render(ctx, notif, models.ContactPoint{Type: "email", Configuration: empty})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(r.Subject, "ERROR") || r.Text != notif.Subject || r.HTML != "<b>pH (station 7)</b>" {
+		t.Errorf("email preview = %+v", r)
+	}
+}
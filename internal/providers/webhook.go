@@ -0,0 +1,133 @@
+package providers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+)
+
+// webhookConfig holds the target and signing secret for a webhook contact point.
+type webhookConfig struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers"`
+	Secret  string            `json:"secret"`
+}
+
+// webhookMethods lists the HTTP methods a webhook contact point may use
+var webhookMethods = map[string]bool{
+	http.MethodPost:  true,
+	http.MethodPut:   true,
+	http.MethodPatch: true,
+}
+
+// WebhookProvider posts notifications as signed JSON to an arbitrary HTTP endpoint.
+type WebhookProvider struct {
+	logger *logging.Logger
+}
+
+// NewWebhookProvider constructs a WebhookProvider
+func NewWebhookProvider(logger *logging.Logger) *WebhookProvider {
+	return &WebhookProvider{logger: logger}
+}
+
+// Name implements Provider
+func (p *WebhookProvider) Name() string {
+	return "webhook"
+}
+
+// Capabilities implements Provider
+func (p *WebhookProvider) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+// ValidateConfig implements Provider
+func (p *WebhookProvider) ValidateConfig(_ context.Context, cfg map[string]interface{}) error {
+	wCfg, err := parseWebhookConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if err := validateURL(wCfg.URL); err != nil {
+		return fmt.Errorf("webhook contact point: %w", err)
+	}
+	if !webhookMethods[wCfg.Method] {
+		return fmt.Errorf("unsupported webhook method %q", wCfg.Method)
+	}
+	if wCfg.Secret == "" {
+		return fmt.Errorf("secret is required in configuration for webhook contact point")
+	}
+	return nil
+}
+
+// Send implements Provider
+func (p *WebhookProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	wCfg, err := parseWebhookConfig(cp.Configuration)
+	if err != nil {
+		return fmt.Errorf("invalid webhook configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	if wCfg.Secret == "" {
+		return fmt.Errorf("missing secret in webhook configuration for contact point %s", uuid.UUID(cp.ID))
+	}
+
+	r, err := p.Render(ctx, notif, cp)
+	if err != nil {
+		return err
+	}
+	payload := r.Payload
+
+	return utils.Retry(ctx, p.logger, 3, time.Second, func() error {
+		// Sign each attempt with a fresh timestamp so receivers can reject replays
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers := map[string]string{}
+		for k, v := range wCfg.Headers {
+			headers[k] = v
+		}
+		headers["Content-Type"] = "application/json"
+		headers["X-Timestamp"] = timestamp
+		headers["X-Signature"] = "sha256=" + signWebhook(wCfg.Secret, timestamp, payload)
+
+		if _, err := doRequest(ctx, wCfg.Method, wCfg.URL, headers, payload); err != nil {
+			return retryableHTTPError(fmt.Errorf("webhook delivery failed: %w", err))
+		}
+		p.logger.Infof("Webhook delivered to %s", wCfg.URL)
+		return nil
+	})
+}
+
+// Render implements Provider. The notification is posted as JSON.
+func (p *WebhookProvider) Render(_ context.Context, notif models.Notification, _ models.ContactPoint) (Rendered, error) {
+	return renderJSON(p.Name(), notif)
+}
+
+// parseWebhookConfig decodes a webhook configuration and applies defaults
+func parseWebhookConfig(cfg map[string]interface{}) (webhookConfig, error) {
+	var wCfg webhookConfig
+	if err := decodeConfig(cfg, &wCfg); err != nil {
+		return webhookConfig{}, err
+	}
+	wCfg.Method = strings.ToUpper(wCfg.Method)
+	if wCfg.Method == "" {
+		wCfg.Method = http.MethodPost
+	}
+	return wCfg, nil
+}
+
+// signWebhook returns the hex HMAC-SHA256 of "<timestamp>.<payload>" keyed with secret.
+func signWebhook(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
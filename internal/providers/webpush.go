@@ -0,0 +1,354 @@
+package providers
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"notification-service/internal/config"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+)
+
+const (
+	// webPushRecordSize is the aes128gcm record size; the whole payload fits in one record
+	webPushRecordSize = 4096
+	// webPushMaxPayload keeps the plaintext within one record after the header, delimiter and GCM tag
+	webPushMaxPayload = webPushRecordSize - 86 - 1 - 16
+	// vapidTokenTTL is how long a VAPID JWT stays valid (the spec allows at most 24h)
+	vapidTokenTTL = 12 * time.Hour
+)
+
+// webPushConfig holds a browser PushSubscription for a webpush contact point.
+type webPushConfig struct {
+	Endpoint string `json:"endpoint"`
+	P256dh   string `json:"p256dh"`
+	Auth     string `json:"auth"`
+}
+
+// webPushMessage is the JSON payload handed to the dashboard service worker
+type webPushMessage struct {
+	Title string                 `json:"title"`
+	Body  string                 `json:"body"`
+	Tag   string                 `json:"tag"`
+	Data  map[string]interface{} `json:"data"`
+}
+
+// WebPushProvider delivers encrypted Web Push messages (RFC 8291) authenticated with VAPID (RFC 8292).
+type WebPushProvider struct {
+	cfg    config.Config
+	logger *logging.Logger
+}
+
+// NewWebPushProvider constructs a WebPushProvider
+func NewWebPushProvider(cfg config.Config, logger *logging.Logger) *WebPushProvider {
+	return &WebPushProvider{cfg: cfg, logger: logger}
+}
+
+// Name implements Provider
+func (p *WebPushProvider) Name() string {
+	return "webpush"
+}
+
+// Capabilities implements Provider
+func (p *WebPushProvider) Capabilities() Capabilities {
+	return Capabilities{MaxLength: webPushMaxPayload}
+}
+
+// ValidateConfig implements Provider
+func (p *WebPushProvider) ValidateConfig(_ context.Context, cfg map[string]interface{}) error {
+	var wpCfg webPushConfig
+	if err := decodeConfig(cfg, &wpCfg); err != nil {
+		return err
+	}
+	if err := validateURL(wpCfg.Endpoint); err != nil {
+		return fmt.Errorf("webpush contact point endpoint: %w", err)
+	}
+	if _, err := decodeUAPublicKey(wpCfg.P256dh); err != nil {
+		return err
+	}
+	auth, err := decodeBase64URL(wpCfg.Auth)
+	if err != nil || len(auth) != 16 {
+		return fmt.Errorf("auth must be a base64url-encoded 16-byte secret")
+	}
+	return nil
+}
+
+// Send implements Provider. A 404 or 410 from the push service means the
+// subscription has expired and is reported as ErrContactPointGone.
+func (p *WebPushProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	var wpCfg webPushConfig
+	if err := decodeConfig(cp.Configuration, &wpCfg); err != nil {
+		return fmt.Errorf("invalid webpush configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+
+	vapidKey, err := parseVAPIDPrivateKey(p.cfg.WebPush.VAPIDPrivateKey)
+	if err != nil {
+		return err
+	}
+
+	r, err := p.Render(ctx, notif, cp)
+	if err != nil {
+		return err
+	}
+	body, err := encryptWebPush(r.Payload, wpCfg)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt push message for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+
+	return utils.Retry(ctx, p.logger, 3, time.Second, func() error {
+		authz, err := vapidAuthorization(wpCfg.Endpoint, p.cfg.WebPush.Subject, vapidKey)
+		if err != nil {
+			return utils.Permanent(err)
+		}
+		headers := map[string]string{
+			"Content-Type":     "application/octet-stream",
+			"Content-Encoding": "aes128gcm",
+			"TTL":              strconv.Itoa(p.cfg.WebPush.TTL),
+			"Urgency":          webPushUrgency(notif),
+			"Authorization":    authz,
+		}
+		if _, err := doRequest(ctx, http.MethodPost, wpCfg.Endpoint, headers, body); err != nil {
+			var statusErr *StatusError
+			if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone) {
+				return utils.Permanent(fmt.Errorf("%w: push subscription expired (%d)", ErrContactPointGone, statusErr.StatusCode))
+			}
+			return retryableHTTPError(fmt.Errorf("failed to deliver push message: %w", err))
+		}
+		p.logger.Infof("Web push delivered for contact point %s", uuid.UUID(cp.ID))
+		return nil
+	})
+}
+
+// Render implements Provider. Payload is the push message before it is encrypted for the subscription.
+func (p *WebPushProvider) Render(_ context.Context, notif models.Notification, _ models.ContactPoint) (Rendered, error) {
+	return renderJSON(p.Name(), buildWebPushMessage(notif))
+}
+
+// VAPIDPublicKey returns the base64url application server key that browsers pass to
+// pushManager.subscribe, deriving it from the private key when not configured.
+func VAPIDPublicKey(cfg config.Config) (string, error) {
+	if cfg.WebPush.VAPIDPublicKey != "" {
+		return cfg.WebPush.VAPIDPublicKey, nil
+	}
+	key, err := parseVAPIDPrivateKey(cfg.WebPush.VAPIDPrivateKey)
+	if err != nil {
+		return "", err
+	}
+	pub, err := key.PublicKey.ECDH()
+	if err != nil {
+		return "", fmt.Errorf("invalid VAPID key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(pub.Bytes()), nil
+}
+
+// buildWebPushMessage renders the notification for the browser; the tag makes a
+// resolved message replace the firing one on screen.
+func buildWebPushMessage(notif models.Notification) webPushMessage {
+	ac := notif.Context
+	return webPushMessage{
+		Title: alertTitle(notif),
+		Body:  truncate(notif.Body, 1024),
+		Tag:   uuid.UUID(notif.RequestID).String(),
+		Data: map[string]interface{}{
+			"request_id":  uuid.UUID(notif.RequestID).String(),
+			"type":        notif.Type,
+			"severity":    notif.Severity,
+			"station_id":  ac.StationID,
+			"metric_name": ac.MetricName,
+			"value":       ac.Value,
+			"threshold":   ac.Threshold,
+		},
+	}
+}
+
+// webPushUrgency maps severity onto the RFC 8030 Urgency header
+func webPushUrgency(notif models.Notification) string {
+	if isResolved(notif) {
+		return "normal"
+	}
+	switch levelOf(notif.Severity) {
+	case severityCritical, severityError:
+		return "high"
+	case severityWarning:
+		return "normal"
+	default:
+		return "low"
+	}
+}
+
+// encryptWebPush encrypts plaintext for a subscription using the aes128gcm content coding (RFC 8291).
+func encryptWebPush(plaintext []byte, sub webPushConfig) ([]byte, error) {
+	if len(plaintext) > webPushMaxPayload {
+		return nil, fmt.Errorf("payload of %d bytes exceeds %d", len(plaintext), webPushMaxPayload)
+	}
+	uaPublic, err := decodeUAPublicKey(sub.P256dh)
+	if err != nil {
+		return nil, err
+	}
+	authSecret, err := decodeBase64URL(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	// Ephemeral application server key pair and salt, fresh for every message
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return sealWebPush(plaintext, uaPublic, authSecret, asPrivate, salt)
+}
+
+// sealWebPush encrypts plaintext as a single aes128gcm record with the given
+// application server key pair and salt, and prepends the content coding header.
+func sealWebPush(plaintext []byte, uaPublic *ecdh.PublicKey, authSecret []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("ECDH failed: %w", err)
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public, 32)
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic.Bytes()...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm := hkdfSHA256(authSecret, ecdhSecret, keyInfo, 32)
+
+	cek := hkdfSHA256(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdfSHA256(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// Single final record: plaintext followed by the 0x02 padding delimiter
+	record := append(append([]byte{}, plaintext...), 0x02)
+
+	// Header: salt(16) || rs(4) || idlen(1) || keyid(as_public)
+	header := make([]byte, 0, 21+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// hkdfSHA256 derives length bytes (at most 32) with HKDF-SHA256 (RFC 5869).
+func hkdfSHA256(salt, ikm, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(ikm)
+	prk := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, prk)
+	expand.Write(info)
+	expand.Write([]byte{0x01})
+	return expand.Sum(nil)[:length]
+}
+
+// vapidAuthorization builds the "vapid t=<jwt>, k=<key>" Authorization header for endpoint.
+func vapidAuthorization(endpoint, subject string, key *ecdsa.PrivateKey) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid push endpoint: %w", err)
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(vapidTokenTTL).Unix(),
+		"sub": subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+	// JWS ES256 signatures are the fixed-width concatenation r || s
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	pub, err := key.PublicKey.ECDH()
+	if err != nil {
+		return "", fmt.Errorf("invalid VAPID key: %w", err)
+	}
+	return fmt.Sprintf("vapid t=%s.%s, k=%s", signingInput, base64.RawURLEncoding.EncodeToString(sig),
+		base64.RawURLEncoding.EncodeToString(pub.Bytes())), nil
+}
+
+// parseVAPIDPrivateKey decodes the base64url raw P-256 scalar used by web-push tooling.
+func parseVAPIDPrivateKey(encoded string) (*ecdsa.PrivateKey, error) {
+	if encoded == "" {
+		return nil, fmt.Errorf("VAPID private key is not configured")
+	}
+	raw, err := decodeBase64URL(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key encoding: %w", err)
+	}
+	ecdhKey, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	// Round-trip through PKCS#8 to obtain an ecdsa key for signing
+	der, err := x509.MarshalPKCS8PrivateKey(ecdhKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid VAPID private key")
+	}
+	return key, nil
+}
+
+// decodeUAPublicKey parses the subscription's p256dh key (uncompressed P-256 point)
+func decodeUAPublicKey(encoded string) (*ecdh.PublicKey, error) {
+	raw, err := decodeBase64URL(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh encoding: %w", err)
+	}
+	key, err := ecdh.P256().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	return key, nil
+}
+
+// decodeBase64URL accepts base64url with or without padding, as browsers emit either.
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// Test vectors from RFC 8291 Appendix A
+const (
+	rfc8291Plaintext  = "V2hlbiBJIGdyb3cgdXAsIEkgd2FudCB0byBiZSBhIHdhdGVybWVsb24"
+	rfc8291ASPrivate  = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+	rfc8291UAPublic   = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	rfc8291UAPrivate  = "q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"
+	rfc8291Salt       = "DGv6ra1nlYgDCS1FRnbzlw"
+	rfc8291AuthSecret = "BTBZMqHH6r4Tts7J_aSIgg"
+	rfc8291Message    = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+)
+
+func mustDecodeBase64URL(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := decodeBase64URL(s)
+	if err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+	return b
+}
+
+func TestSealWebPushRFC8291(t *testing.T) {
+	asPrivate, err := ecdh.P256().NewPrivateKey(mustDecodeBase64URL(t, rfc8291ASPrivate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uaPublic, err := decodeUAPublicKey(rfc8291UAPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := sealWebPush(mustDecodeBase64URL(t, rfc8291Plaintext), uaPublic,
+		mustDecodeBase64URL(t, rfc8291AuthSecret), asPrivate, mustDecodeBase64URL(t, rfc8291Salt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc := base64.RawURLEncoding.EncodeToString(got); enc != rfc8291Message {
+		t.Errorf("encrypted message mismatch\ngot  %s\nwant %s", enc, rfc8291Message)
+	}
+}
+
+func TestEncryptWebPushDecrypts(t *testing.T) {
+	plaintext := []byte(`{"title":"[CRITICAL] pH out of range"}`)
+	body, err := encryptWebPush(plaintext, webPushConfig{P256dh: rfc8291UAPublic, Auth: rfc8291AuthSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Decrypt as the user agent would (RFC 8291 section 3.4)
+	salt, rs, idlen := body[:16], body[16:20], int(body[20])
+	if got := int(rs[0])<<24 | int(rs[1])<<16 | int(rs[2])<<8 | int(rs[3]); got != webPushRecordSize {
+		t.Fatalf("record size = %d, want %d", got, webPushRecordSize)
+	}
+	asPublic, err := ecdh.P256().NewPublicKey(body[21 : 21+idlen])
+	if err != nil {
+		t.Fatal(err)
+	}
+	uaPrivate, err := ecdh.P256().NewPrivateKey(mustDecodeBase64URL(t, rfc8291UAPrivate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdhSecret, err := uaPrivate.ECDH(asPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyInfo := append([]byte("WebPush: info\x00"), uaPrivate.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublic.Bytes()...)
+	ikm := hkdfSHA256(mustDecodeBase64URL(t, rfc8291AuthSecret), ecdhSecret, keyInfo, 32)
+	cek := hkdfSHA256(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdfSHA256(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := gcm.Open(nil, nonce, body[21+idlen:], nil)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if string(record) != string(plaintext)+"\x02" {
+		t.Errorf("record = %q, want plaintext followed by 0x02", record)
+	}
+}
+
+func TestVAPIDAuthorization(t *testing.T) {
+	key, err := parseVAPIDPrivateKey(rfc8291UAPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authz, err := vapidAuthorization("https://push.example.net/p/abc?x=1", "mailto:ops@example.com", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, k, ok := strings.Cut(strings.TrimPrefix(authz, "vapid t="), ", k=")
+	if !strings.HasPrefix(authz, "vapid t=") || !ok {
+		t.Fatalf("malformed Authorization header %q", authz)
+	}
+	if k != rfc8291UAPublic {
+		t.Errorf("k = %s, want %s", k, rfc8291UAPublic)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("JWT has %d parts, want 3", len(parts))
+	}
+	var header, claims map[string]interface{}
+	if err := json.Unmarshal(mustDecodeBase64URL(t, parts[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(mustDecodeBase64URL(t, parts[1]), &claims); err != nil {
+		t.Fatal(err)
+	}
+	if header["alg"] != "ES256" {
+		t.Errorf("alg = %v, want ES256", header["alg"])
+	}
+	if claims["aud"] != "https://push.example.net" || claims["sub"] != "mailto:ops@example.com" {
+		t.Errorf("unexpected claims %v", claims)
+	}
+
+	sig := mustDecodeBase64URL(t, parts[2])
+	if len(sig) != 64 {
+		t.Fatalf("signature is %d bytes, want 64", len(sig))
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(&key.PublicKey, digest[:], r, s) {
+		t.Error("ES256 signature does not verify")
+	}
+}
@@ -0,0 +1,315 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"notification-service/internal/config"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+)
+
+const (
+	// zaloTextLimit is the maximum length of a Zalo OA text message
+	zaloTextLimit = 2000
+	// zaloRefreshMargin refreshes an access token this long before it expires
+	zaloRefreshMargin = 5 * time.Minute
+)
+
+// Zalo OpenAPI error codes that need special handling
+const (
+	zaloErrInvalidToken = -216 // access token invalid or revoked
+	zaloErrExpiredToken = -124 // access token expired
+	zaloErrRateLimit    = -32  // too many requests
+)
+
+// errZaloTokenExpired means the access token was refused and should be refreshed.
+var errZaloTokenExpired = errors.New("zalo access token expired")
+
+// zaloConfig holds the Official Account credentials and recipient for a Zalo contact point.
+type zaloConfig struct {
+	UserID       string `json:"user_id"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresAt    string `json:"expires_at,omitempty"` // RFC 3339, written back after a refresh
+	AppID        string `json:"app_id,omitempty"`
+	AppSecret    string `json:"app_secret,omitempty"`
+}
+
+// zaloMessage is the OA customer service message payload
+type zaloMessage struct {
+	Recipient struct {
+		UserID string `json:"user_id"`
+	} `json:"recipient"`
+	Message struct {
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+// zaloResponse is the envelope of every OpenAPI response; Error is 0 on success
+type zaloResponse struct {
+	Error   int    `json:"error"`
+	Message string `json:"message"`
+}
+
+// zaloTokenResponse is the OAuth access token response. expires_in is sent as a string.
+type zaloTokenResponse struct {
+	AccessToken      string          `json:"access_token"`
+	RefreshToken     string          `json:"refresh_token"`
+	ExpiresIn        json.RawMessage `json:"expires_in"`
+	Error            int             `json:"error"`
+	ErrorName        string          `json:"error_name"`
+	ErrorDescription string          `json:"error_description"`
+}
+
+// zaloTokens is the current token pair of a contact point
+type zaloTokens struct {
+	access    string
+	refresh   string
+	expiresAt time.Time // zero when unknown
+	origin    string    // access token in the configuration this pair was refreshed from
+}
+
+// ZaloProvider sends notifications as Zalo Official Account messages.
+type ZaloProvider struct {
+	cfg    config.Config
+	store  ContactPointConfigStore // persists rotated tokens, nil keeps them in memory only
+	logger *logging.Logger
+
+	// Zalo refresh tokens are single use, so refreshes are serialised per contact point
+	// and the newest pair is kept here in case the configuration passed to Send is stale
+	mu         sync.Mutex // guards tokens and refreshing, never held across a request
+	tokens     map[[16]byte]zaloTokens
+	refreshing map[[16]byte]*sync.Mutex
+}
+
+// NewZaloProvider constructs a ZaloProvider
+func NewZaloProvider(cfg config.Config, store ContactPointConfigStore, logger *logging.Logger) *ZaloProvider {
+	return &ZaloProvider{cfg: cfg, store: store, logger: logger, tokens: make(map[[16]byte]zaloTokens), refreshing: make(map[[16]byte]*sync.Mutex)}
+}
+
+// Name implements Provider
+func (p *ZaloProvider) Name() string {
+	return "zalo"
+}
+
+// Capabilities implements Provider
+func (p *ZaloProvider) Capabilities() Capabilities {
+	return Capabilities{MaxLength: zaloTextLimit}
+}
+
+// ValidateConfig implements Provider
+func (p *ZaloProvider) ValidateConfig(_ context.Context, cfg map[string]interface{}) error {
+	var zCfg zaloConfig
+	if err := decodeConfig(cfg, &zCfg); err != nil {
+		return err
+	}
+	if zCfg.UserID == "" {
+		return fmt.Errorf("user_id is required in configuration for zalo contact point")
+	}
+	if zCfg.AccessToken == "" {
+		return fmt.Errorf("access_token is required in configuration for zalo contact point")
+	}
+	if zCfg.RefreshToken != "" && (p.appID(zCfg) == "" || p.appSecret(zCfg) == "") {
+		return fmt.Errorf("app_id and app_secret (or ZALO_APP_ID and ZALO_APP_SECRET) are required to refresh the zalo access token")
+	}
+	if zCfg.ExpiresAt != "" {
+		if _, err := time.Parse(time.RFC3339, zCfg.ExpiresAt); err != nil {
+			return fmt.Errorf("invalid expires_at: %w", err)
+		}
+	}
+	return nil
+}
+
+// Send implements Provider
+func (p *ZaloProvider) Send(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
+	var zCfg zaloConfig
+	if err := decodeConfig(cp.Configuration, &zCfg); err != nil {
+		return fmt.Errorf("invalid Zalo configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	if zCfg.UserID == "" || zCfg.AccessToken == "" {
+		return fmt.Errorf("missing user_id or access_token in Zalo configuration for contact point %s", uuid.UUID(cp.ID))
+	}
+
+	r, err := p.Render(ctx, notif, cp)
+	if err != nil {
+		return err
+	}
+	payload := r.Payload
+
+	return utils.Retry(ctx, p.logger, 3, time.Second, func() error {
+		var err error
+		tokens := p.currentTokens(cp.ID, zCfg)
+		if !tokens.expiresAt.IsZero() && time.Until(tokens.expiresAt) < zaloRefreshMargin && tokens.refresh != "" {
+			if tokens, err = p.refresh(ctx, cp.ID, zCfg, tokens); err != nil {
+				return err
+			}
+		}
+		err = p.sendMessage(ctx, tokens.access, payload)
+		if errors.Is(err, errZaloTokenExpired) && tokens.refresh != "" {
+			p.logger.Infof("Zalo access token for contact point %s was refused, refreshing", uuid.UUID(cp.ID))
+			if tokens, err = p.refresh(ctx, cp.ID, zCfg, tokens); err != nil {
+				return err
+			}
+			err = p.sendMessage(ctx, tokens.access, payload)
+		}
+		if errors.Is(err, errZaloTokenExpired) {
+			return utils.Permanent(err)
+		}
+		if err != nil {
+			return err
+		}
+		p.logger.Infof("Zalo message sent for contact point %s", uuid.UUID(cp.ID))
+		return nil
+	})
+}
+
+// Render implements Provider
+func (p *ZaloProvider) Render(_ context.Context, notif models.Notification, cp models.ContactPoint) (Rendered, error) {
+	var zCfg zaloConfig
+	if err := decodeConfig(cp.Configuration, &zCfg); err != nil {
+		return Rendered{}, fmt.Errorf("invalid Zalo configuration for contact point %s: %w", uuid.UUID(cp.ID), err)
+	}
+	// Zalo shows plain text only, laid out like the Telegram message
+	var msg zaloMessage
+	msg.Recipient.UserID = zCfg.UserID
+	msg.Message.Text = truncate(renderChatTemplate(notif, p.Name(), markupPlain, p.logger), zaloTextLimit)
+	return renderJSON(p.Name(), msg)
+}
+
+// sendMessage posts a customer service message with the given access token.
+func (p *ZaloProvider) sendMessage(ctx context.Context, accessToken string, payload []byte) error {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"access_token": accessToken,
+	}
+	body, err := doRequest(ctx, "POST", strings.TrimRight(p.cfg.Zalo.APIBaseURL, "/")+"/v3.0/oa/message/cs", headers, payload)
+	if err != nil {
+		return retryableHTTPError(fmt.Errorf("failed to send Zalo message: %w", err))
+	}
+	var resp zaloResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("invalid Zalo response %q: %w", body, err)
+	}
+	switch resp.Error {
+	case 0:
+		return nil
+	case zaloErrInvalidToken, zaloErrExpiredToken:
+		return fmt.Errorf("%w: %s (error %d)", errZaloTokenExpired, resp.Message, resp.Error)
+	case zaloErrRateLimit:
+		return fmt.Errorf("zalo rate limit: %s (error %d)", resp.Message, resp.Error)
+	default:
+		// e.g. the user has not followed or not interacted with the OA recently
+		return utils.Permanent(fmt.Errorf("zalo refused the message: %s (error %d)", resp.Message, resp.Error))
+	}
+}
+
+// currentTokens returns the newest token pair known for a contact point.
+func (p *ZaloProvider) currentTokens(cpID [16]byte, zCfg zaloConfig) zaloTokens {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Unless the configuration was edited since, e.g. after authorising the OA again
+	if t, ok := p.tokens[cpID]; ok && (zCfg.AccessToken == t.access || zCfg.AccessToken == t.origin) {
+		return t
+	}
+	delete(p.tokens, cpID)
+	t := zaloTokens{access: zCfg.AccessToken, refresh: zCfg.RefreshToken}
+	if zCfg.ExpiresAt != "" {
+		t.expiresAt, _ = time.Parse(time.RFC3339, zCfg.ExpiresAt)
+	}
+	return t
+}
+
+// refresh exchanges the refresh token for a new token pair and persists it.
+// If another send already refreshed since stale was read, that pair is used instead.
+func (p *ZaloProvider) refresh(ctx context.Context, cpID [16]byte, zCfg zaloConfig, stale zaloTokens) (zaloTokens, error) {
+	p.mu.Lock()
+	lock, ok := p.refreshing[cpID]
+	if !ok {
+		lock = &sync.Mutex{}
+		p.refreshing[cpID] = lock
+	}
+	p.mu.Unlock()
+	lock.Lock()
+	defer lock.Unlock()
+
+	p.mu.Lock()
+	t, ok := p.tokens[cpID]
+	p.mu.Unlock()
+	if ok && t.access != stale.access {
+		return t, nil
+	}
+
+	form := url.Values{
+		"refresh_token": {stale.refresh},
+		"app_id":        {p.appID(zCfg)},
+		"grant_type":    {"refresh_token"},
+	}
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+		"secret_key":   p.appSecret(zCfg),
+	}
+	body, err := doRequest(ctx, "POST", strings.TrimRight(p.cfg.Zalo.OAuthBaseURL, "/")+"/v4/oa/access_token", headers, []byte(form.Encode()))
+	if err != nil {
+		return zaloTokens{}, retryableHTTPError(fmt.Errorf("failed to refresh Zalo access token: %w", err))
+	}
+	var resp zaloTokenResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return zaloTokens{}, fmt.Errorf("invalid Zalo token response %q: %w", body, err)
+	}
+	if resp.AccessToken == "" {
+		// The refresh token is used up or revoked, the OA has to be authorised again
+		return zaloTokens{}, utils.Permanent(fmt.Errorf("failed to refresh Zalo access token: %s %s (error %d)",
+			resp.ErrorName, resp.ErrorDescription, resp.Error))
+	}
+
+	t = zaloTokens{access: resp.AccessToken, refresh: resp.RefreshToken, origin: stale.origin}
+	if t.origin == "" {
+		t.origin = stale.access
+	}
+	if t.refresh == "" {
+		t.refresh = stale.refresh
+	}
+	if secs, err := strconv.Atoi(strings.Trim(string(resp.ExpiresIn), `"`)); err == nil {
+		t.expiresAt = time.Now().Add(time.Duration(secs) * time.Second)
+	}
+	p.mu.Lock()
+	p.tokens[cpID] = t
+	p.mu.Unlock()
+
+	if p.store != nil {
+		values := map[string]interface{}{"access_token": t.access, "refresh_token": t.refresh}
+		if !t.expiresAt.IsZero() {
+			values["expires_at"] = t.expiresAt.UTC().Format(time.RFC3339)
+		}
+		if err := p.store.MergeContactPointConfig(ctx, cpID, values); err != nil {
+			// The old refresh token no longer works, so this pair only survives in memory
+			p.logger.Errorf("Failed to store refreshed Zalo tokens for contact point %s: %v", uuid.UUID(cpID), err)
+		}
+	}
+	p.logger.Infof("Zalo access token refreshed for contact point %s", uuid.UUID(cpID))
+	return t, nil
+}
+
+func (p *ZaloProvider) appID(zCfg zaloConfig) string {
+	if zCfg.AppID != "" {
+		return zCfg.AppID
+	}
+	return p.cfg.Zalo.AppID
+}
+
+func (p *ZaloProvider) appSecret(zCfg zaloConfig) string {
+	if zCfg.AppSecret != "" {
+		return zCfg.AppSecret
+	}
+	return p.cfg.Zalo.AppSecret
+}
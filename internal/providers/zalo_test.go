@@ -0,0 +1,128 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"notification-service/internal/config"
+	"notification-service/internal/logging"
+	"notification-service/internal/models"
+)
+
+// fakeConfigStore records configuration merges
+type fakeConfigStore struct {
+	merged map[string]interface{}
+}
+
+func (s *fakeConfigStore) MergeContactPointConfig(_ context.Context, _ [16]byte, values map[string]interface{}) error {
+	s.merged = values
+	return nil
+}
+
+// fakeZalo accepts only validToken and rotates it to "access-2" on refresh
+func fakeZalo(t *testing.T, validToken string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.Path)
+		switch r.URL.Path {
+		case "/v3.0/oa/message/cs":
+			if r.Header.Get("access_token") != validToken {
+				w.Write([]byte(`{"error":-216,"message":"Access token is invalid"}`))
+				return
+			}
+			var msg zaloMessage
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &msg); err != nil || msg.Recipient.UserID != "u1" || msg.Message.Text == "" {
+				t.Errorf("unexpected message %s", body)
+			}
+			w.Write([]byte(`{"error":0,"message":"Success"}`))
+		case "/v4/oa/access_token":
+			body, _ := io.ReadAll(r.Body)
+			form, _ := url.ParseQuery(string(body))
+			if r.Header.Get("secret_key") != "secret" || form.Get("refresh_token") != "refresh-1" || form.Get("app_id") != "app" {
+				w.Write([]byte(`{"error":-14014,"error_name":"Invalid refresh token"}`))
+				return
+			}
+			validToken = "access-2"
+			w.Write([]byte(`{"access_token":"access-2","refresh_token":"refresh-2","expires_in":"90000"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func testZaloProvider(t *testing.T, baseURL string, store ContactPointConfigStore) *ZaloProvider {
+	t.Helper()
+	logger, err := logging.New(t.TempDir(), "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg config.Config
+	cfg.Zalo.APIBaseURL, cfg.Zalo.OAuthBaseURL, cfg.Zalo.AppID, cfg.Zalo.AppSecret = baseURL, baseURL, "app", "secret"
+	return NewZaloProvider(cfg, store, logger)
+}
+
+func zaloContactPoint(access, refresh string) models.ContactPoint {
+	return models.ContactPoint{
+		ID:            [16]byte{1},
+		Type:          "zalo",
+		Configuration: map[string]interface{}{"user_id": "u1", "access_token": access, "refresh_token": refresh},
+	}
+}
+
+func TestZaloSend(t *testing.T) {
+	srv, calls := fakeZalo(t, "access-1")
+	p := testZaloProvider(t, srv.URL, nil)
+	if err := p.Send(context.Background(), models.Notification{Subject: "pH out of range"}, zaloContactPoint("access-1", "refresh-1")); err != nil {
+		t.Fatal(err)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("calls = %v, want a single send", *calls)
+	}
+}
+
+func TestZaloRefreshesExpiredToken(t *testing.T) {
+	srv, calls := fakeZalo(t, "access-2")
+	store := &fakeConfigStore{}
+	p := testZaloProvider(t, srv.URL, store)
+	cp := zaloContactPoint("access-1", "refresh-1")
+
+	if err := p.Send(context.Background(), models.Notification{Subject: "pH out of range"}, cp); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/v3.0/oa/message/cs", "/v4/oa/access_token", "/v3.0/oa/message/cs"}
+	if len(*calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", *calls, want)
+	}
+	if store.merged["access_token"] != "access-2" || store.merged["refresh_token"] != "refresh-2" || store.merged["expires_at"] == nil {
+		t.Errorf("stored tokens = %v", store.merged)
+	}
+
+	// The stale configuration is not used again, the single-use refresh token is spent
+	*calls = nil
+	if err := p.Send(context.Background(), models.Notification{Subject: "pH out of range"}, cp); err != nil {
+		t.Fatal(err)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("calls = %v, want a single send with the refreshed token", *calls)
+	}
+}
+
+func TestZaloRefreshFailureIsPermanent(t *testing.T) {
+	srv, calls := fakeZalo(t, "access-2")
+	p := testZaloProvider(t, srv.URL, nil)
+	if err := p.Send(context.Background(), models.Notification{}, zaloContactPoint("access-1", "revoked")); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(*calls) != 2 {
+		t.Errorf("calls = %v, want one send and one refresh without retries", *calls)
+	}
+}
@@ -2,7 +2,11 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -24,15 +28,16 @@ type WebSocketManager struct {
 
 // Service processes alert Tasks and dispatches Notifications
 type Service struct {
-	db            *db.DB
-	logger        *logging.Logger
-	config        config.Config
-	tasks         chan models.Task
-	ctx           context.Context
-	cancel        context.CancelFunc
-	wg            *sync.WaitGroup
-	providerFuncs map[string]func(context.Context, models.Notification, models.ContactPoint) error
-	wsManager     *WebSocketManager
+	db        *db.DB
+	logger    *logging.Logger
+	config    config.Config
+	tasks     chan models.Task
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        *sync.WaitGroup
+	providers *providers.Registry
+	telegram  *providers.TelegramProvider
+	wsManager *WebSocketManager
 }
 
 // New constructs a services Service
@@ -50,17 +55,84 @@ func New(db *db.DB, logger *logging.Logger, cfg config.Config) *Service {
 			logger:      logger,
 		},
 	}
-	svc.providerFuncs = map[string]func(context.Context, models.Notification, models.ContactPoint) error{
-		"email": func(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
-			return providers.SendEmail(ctx, notif, cp, svc.config, logger)
-		},
-		"telegram": func(ctx context.Context, notif models.Notification, cp models.ContactPoint) error {
-			return providers.SendTelegram(ctx, notif, cp, logger, svc.config)
-		},
-	}
+	svc.providers = providers.NewRegistry()
+	svc.providers.Register(providers.NewEmailProvider(cfg, db, logger))
+	svc.telegram = providers.NewTelegramProvider(cfg, db, db, db, logger)
+	svc.providers.Register(svc.telegram)
+	svc.providers.Register(providers.NewWebhookProvider(logger))
+	svc.providers.Register(providers.NewSlackProvider(logger))
+	svc.providers.Register(providers.NewTeamsProvider(logger))
+	svc.providers.Register(providers.NewDiscordProvider(logger))
+	svc.providers.Register(providers.NewSMSProvider(cfg, logger))
+	svc.providers.Register(providers.NewPagerDutyProvider(cfg, logger))
+	svc.providers.Register(providers.NewWebPushProvider(cfg, logger))
+	svc.providers.Register(providers.NewMatrixProvider(cfg, logger))
+	svc.providers.Register(providers.NewMattermostProvider(logger))
+	svc.providers.Register(providers.NewKafkaProvider(cfg, logger))
+	svc.providers.Register(providers.NewZaloProvider(cfg, db, logger))
 	return svc
 }
 
+// Providers exposes the registry of notification channels
+func (s *Service) Providers() *providers.Registry {
+	return s.providers
+}
+
+// VAPIDPublicKey returns the application server key browsers need to subscribe to web push
+func (s *Service) VAPIDPublicKey() (string, error) {
+	return providers.VAPIDPublicKey(s.config)
+}
+
+// TelegramWebhookHandler returns the handler for updates Telegram posts for a bot
+func (s *Service) TelegramWebhookHandler(botID int64) (http.HandlerFunc, bool) {
+	return s.telegram.WebhookHandler(botID)
+}
+
+// TelegramLinkingEnabled reports whether a service bot is configured to bind chats through /start links
+func (s *Service) TelegramLinkingEnabled() bool {
+	return s.config.Telegram.BotToken != "" && s.config.Telegram.UpdatesMode != "off"
+}
+
+// IssueTelegramLink creates a one-time /start link that binds a Telegram chat to the contact point
+func (s *Service) IssueTelegramLink(ctx context.Context, contactPointID [16]byte) (models.TelegramLink, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return models.TelegramLink{}, fmt.Errorf("failed to generate link token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	private, group, err := s.telegram.TelegramLinkURLs(ctx, token)
+	if err != nil {
+		return models.TelegramLink{}, err
+	}
+	expiresAt := time.Now().Add(time.Duration(s.config.Telegram.LinkTokenTTL) * time.Second)
+	if err := s.db.CreateTelegramLinkToken(ctx, token, contactPointID, expiresAt); err != nil {
+		return models.TelegramLink{}, err
+	}
+	return models.TelegramLink{
+		ContactPointID: uuid.UUID(contactPointID).String(),
+		Token:          token,
+		URL:            private,
+		GroupURL:       group,
+		ExpiresAt:      expiresAt,
+	}, nil
+}
+
+// ValidateContactPoint checks that a contact point has a registered type and a valid configuration
+func (s *Service) ValidateContactPoint(ctx context.Context, cp models.ContactPoint) error {
+	provider, err := s.providers.Get(cp.Type)
+	if err != nil {
+		return err
+	}
+	return provider.ValidateConfig(ctx, cp.Configuration)
+}
+
+// ValidateTemplate checks that a message template is written for a channel that renders
+// templates and that it parses and executes within the sandbox
+func (s *Service) ValidateTemplate(t models.Template) error {
+	return providers.ValidateTemplate(t)
+}
+
 // Logger exposes the Service's logger
 func (s *Service) Logger() *logging.Logger {
 	return s.logger
@@ -73,6 +145,24 @@ func (s *Service) Start(wg *sync.WaitGroup) {
 		s.wg.Add(1)
 		go s.worker(i)
 	}
+
+	// Listen for Telegram button presses under messages sent before this start
+	go func() {
+		cps, err := s.db.GetContactPointsByType(s.ctx, "telegram")
+		if err != nil {
+			s.logger.Errorf("Failed to load Telegram contact points: %v", err)
+			return
+		}
+		s.telegram.Listen(cps)
+	}()
+}
+
+// Close stops the workers and releases provider resources
+func (s *Service) Close() {
+	s.cancel()
+	if err := s.providers.Close(); err != nil {
+		s.logger.Errorf("Failed to close providers: %v", err)
+	}
 }
 
 // QueueTask enqueues a Task for processing
@@ -120,6 +210,18 @@ func (s *Service) handleTask(task models.Task) {
 		return
 	}
 
+	// An alert silenced from a chat stays quiet until the silence ends, its resolution still goes out
+	silenced := task.Silenced
+	if silenced == 0 && task.TypeMessage != "resolved" {
+		until, err := s.db.GetAlertSilencedUntil(s.ctx, reqID)
+		if err != nil {
+			s.logger.Errorf("Failed to check silence of alert %s: %v", task.RequestID, err)
+		} else if time.Now().Before(until) {
+			s.logger.Infof("Alert %s is silenced until %s", task.RequestID, until.Format(time.RFC3339))
+			silenced = 1
+		}
+	}
+
 	// Process each policy
 	for _, pol := range policies {
 		if !evaluateCondition(pol.ConditionType, task.Severity, int(pol.Severity)) {
@@ -132,40 +234,8 @@ func (s *Service) handleTask(task models.Task) {
 			continue
 		}
 
-		// Prepare services body
-		body := fmt.Sprintf(
-			"%s\nStation: %d\nMetric: %s\nValue: %.2f\nThreshold: %.2f",
-			task.Body,
-			task.StationID,
-			task.MetricName,
-			task.Value,
-			task.Threshold,
-		)
-
 		// Create Notification record
-		notif := models.Notification{
-			ID:                   reqID,
-			CreatedAt:            time.Now(),
-			UpdatedAt:            time.Now(),
-			Type:                 task.TypeMessage,
-			Subject:              task.Subject,
-			Body:                 body,
-			NotificationPolicyID: pol.ID,
-			Status:               "pending",
-			RecipientID:          task.RecipientID,
-			RequestID:            reqID,
-			Silenced:             task.Silenced,
-			Context: models.AlertContext{
-				StationID:    task.StationID,
-				MetricID:     task.MetricID,
-				MetricName:   task.MetricName,
-				Operator:     task.Operator,
-				Threshold:    task.Threshold,
-				ThresholdMin: task.ThresholdMin,
-				ThresholdMax: task.ThresholdMax,
-				Value:        task.Value,
-			},
-		}
+		notif := s.newNotification(task, reqID, pol.ID, silenced)
 
 		// Persist services
 		if err := s.db.CreateNotification(s.ctx, notif); err != nil {
@@ -174,9 +244,22 @@ func (s *Service) handleTask(task models.Task) {
 		}
 
 		if notif.Silenced == 0 {
+			// Resolve provider for the contact point type
+			provider, err := s.providers.Get(pol.ContactPoint.Type)
+			if err != nil {
+				s.logger.Errorf("Policy %s: %v", uuid.UUID(pol.ID).String(), err)
+				_ = s.db.UpdateNotificationStatus(s.ctx, task.RequestID, pol.ContactPoint.Type, "unsupported", err.Error())
+				continue
+			}
+
+			// A template attached to the policy or its contact point replaces the built-in format
+			notif.Template, err = s.db.GetDispatchTemplate(s.ctx, pol.ID, pol.ContactPoint.ID, pol.ContactPoint.Type)
+			if err != nil {
+				s.logger.Errorf("Policy %s: %v, using the built-in format", uuid.UUID(pol.ID).String(), err)
+			}
+
 			// Dispatch via provider
-			provider := s.providerFuncs[pol.ContactPoint.Type]
-			err = provider(s.ctx, notif, *pol.ContactPoint)
+			err = provider.Send(s.ctx, notif, *pol.ContactPoint)
 
 			// Send via WebSocket
 			message := []byte(fmt.Sprintf("New alert: %s", notif.Subject))
@@ -187,6 +270,9 @@ func (s *Service) handleTask(task models.Task) {
 			if err != nil {
 				final = "failed"
 				s.logger.Errorf("Dispatch error via %s: %v", pol.ContactPoint.Type, err)
+				if errors.Is(err, providers.ErrContactPointGone) {
+					s.retireContactPoint(*pol.ContactPoint)
+				}
 			}
 			_ = s.db.UpdateNotificationStatus(s.ctx, task.RequestID, pol.ContactPoint.Type, final, fmt.Sprintf("%v", err))
 			s.logger.Infof("Policy %s dispatched %s via %s", uuid.UUID(pol.ID).String(), final, pol.ContactPoint.Type)
@@ -197,6 +283,77 @@ func (s *Service) handleTask(task models.Task) {
 	}
 }
 
+// newNotification builds the notification of a task for one policy, with the body rendered
+func (s *Service) newNotification(task models.Task, reqID, policyID [16]byte, silenced int) models.Notification {
+	notif := models.Notification{
+		ID:                   reqID,
+		CreatedAt:            time.Now(),
+		UpdatedAt:            time.Now(),
+		Type:                 task.TypeMessage,
+		Subject:              task.Subject,
+		Body:                 task.Body,
+		NotificationPolicyID: policyID,
+		Status:               "pending",
+		RecipientID:          task.RecipientID,
+		RequestID:            reqID,
+		Silenced:             silenced,
+		Severity:             task.Severity,
+		Context: models.AlertContext{
+			StationID:    task.StationID,
+			MetricID:     task.MetricID,
+			MetricName:   task.MetricName,
+			Operator:     task.Operator,
+			Threshold:    task.Threshold,
+			ThresholdMin: task.ThresholdMin,
+			ThresholdMax: task.ThresholdMax,
+			Value:        task.Value,
+		},
+	}
+
+	// Prepare services body
+	if body, err := providers.RenderBody(notif); err != nil {
+		s.logger.Errorf("Failed to render body of alert %s: %v", task.RequestID, err)
+	} else {
+		notif.Body = body
+	}
+	return notif
+}
+
+// Preview renders what a task would send to a contact point, without sending it or
+// storing anything. The template defaults to the one attached to the contact point.
+func (s *Service) Preview(ctx context.Context, task models.Task, cp models.ContactPoint, tmpl *models.Template) (providers.Rendered, error) {
+	reqID, err := uuid.Parse(task.RequestID)
+	if err != nil {
+		return providers.Rendered{}, fmt.Errorf("invalid alert_id %q: %w", task.RequestID, err)
+	}
+	provider, err := s.providers.Get(cp.Type)
+	if err != nil {
+		return providers.Rendered{}, err
+	}
+	if tmpl == nil && cp.ID != [16]byte{} {
+		if tmpl, err = s.db.GetDispatchTemplate(ctx, [16]byte{}, cp.ID, cp.Type); err != nil {
+			return providers.Rendered{}, err
+		}
+	}
+	if tmpl != nil && tmpl.Channel != cp.Type {
+		return providers.Rendered{}, fmt.Errorf("template is written for %s, not for a %s contact point", tmpl.Channel, cp.Type)
+	}
+
+	notif := s.newNotification(task, reqID, [16]byte{}, 0)
+	notif.Template = tmpl
+	return provider.Render(ctx, notif, cp)
+}
+
+// retireContactPoint soft-deletes a contact point whose destination no longer exists
+func (s *Service) retireContactPoint(cp models.ContactPoint) {
+	id := uuid.UUID(cp.ID).String()
+	if err := s.db.DeleteContactPoint(s.ctx, id); err != nil {
+		s.logger.Errorf("Failed to retire contact point %s: %v", id, err)
+		return
+	}
+	s.logger.Warnf("Contact point %s (%s) is gone and has been deleted", id, cp.Type)
+}
+
 // evaluateCondition checks if alertSeverity satisfies the policy condition
 func evaluateCondition(cond string, alertSeverity, policySeverity int) bool {
 	switch cond {
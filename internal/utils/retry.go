@@ -1,19 +1,80 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"notification-service/internal/logging"
 	"time"
 )
 
-func Retry(logger *logging.Logger, maxAttempts int, delay time.Duration, fn func() error) error {
+// PermanentError wraps an error that retrying cannot fix.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent marks err so that Retry returns it immediately instead of trying again.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// RetryAfterError wraps an error whose source asked to wait a given time before trying again.
+type RetryAfterError struct {
+	Err   error
+	Delay time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter marks err so that Retry waits delay, instead of its fixed delay, before the next attempt.
+func RetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryAfterError{Err: err, Delay: delay}
+}
+
+// Retry calls fn until it succeeds, up to maxAttempts times, waiting delay between attempts.
+// It stops early on a permanent error or when ctx is done.
+func Retry(ctx context.Context, logger *logging.Logger, maxAttempts int, delay time.Duration, fn func() error) error {
 	var lastErr error
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		if err := fn(); err != nil {
+			var perm *PermanentError
+			if errors.As(err, &perm) {
+				logger.Errorf("Attempt %d/%d failed permanently: %v", attempt, maxAttempts, perm.Err)
+				return perm.Err
+			}
 			lastErr = err
 			logger.Errorf("Attempt %d/%d failed: %v", attempt, maxAttempts, err)
 			if attempt < maxAttempts {
-				time.Sleep(delay)
+				wait := delay
+				var after *RetryAfterError
+				if errors.As(err, &after) && after.Delay > 0 {
+					wait = after.Delay
+				}
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return fmt.Errorf("gave up after %d attempts: %w (last error: %v)", attempt, ctx.Err(), lastErr)
+				}
 			}
 			continue
 		}